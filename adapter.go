@@ -0,0 +1,117 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// WithParams adapts a function that takes route parameters as positional
+// string arguments into a HandlerFunc. The function's first result is sent
+// as a 200 JSON response; a non-nil error is passed to the ErrorHandler.
+//
+//	r.Get("/users/:id", router.WithParams(func(id string) (interface{}, error) {
+//	    return findUser(id)
+//	}))
+//
+//	r.Get("/users/:user_id/posts/:post_id", router.WithParams(func(userID, postID string) (interface{}, error) {
+//	    return findPost(userID, postID)
+//	}))
+//
+// Arguments are matched to the route's parameters by position, in the order
+// they appear in the route pattern.
+//
+// Panics if fn is not a function of string arguments returning (T, error).
+// Registering the handler on a route with a different number of parameters
+// panics too, or fails for the Try variants such as TryGet.
+func WithParams(fn interface{}) HandlerFunc {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic(fmt.Sprintf("WithParams: expected a function, got %s", ft))
+	}
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.In(i).Kind() != reflect.String {
+			panic(fmt.Sprintf("WithParams: argument %d of %s must be a string", i, ft))
+		}
+	}
+	if ft.NumOut() != 2 || !ft.Out(1).Implements(errorType) {
+		panic(fmt.Sprintf("WithParams: %s must return (T, error)", ft))
+	}
+
+	return func(c *Context) error {
+		if c == paramsProbe {
+			return paramsFunc{ft}
+		}
+
+		names := paramNames(c.pattern)
+		if len(names) != ft.NumIn() {
+			panic(fmt.Sprintf("WithParams: route %s has %d parameters %v but handler %s takes %d", c.pattern, len(names), names, ft, ft.NumIn()))
+		}
+
+		args := make([]reflect.Value, len(names))
+		for i, name := range names {
+			args[i] = reflect.ValueOf(c.Param(name)).Convert(ft.In(i))
+		}
+
+		out := fv.Call(args)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, out[0].Interface())
+	}
+}
+
+// withParamsCode is the code pointer shared by the handlers WithParams
+// returns, which marks them for checkParams
+var withParamsCode = reflect.ValueOf(WithParams(func() (interface{}, error) { return nil, nil })).Pointer()
+
+// paramsProbe is passed to a WithParams handler in place of a request's
+// Context, to ask it for the function it calls
+var paramsProbe = new(Context)
+
+// paramsFunc is the answer of a WithParams handler given paramsProbe
+type paramsFunc struct {
+	typ reflect.Type
+}
+
+func (f paramsFunc) Error() string {
+	return fmt.Sprintf("WithParams handler for %s", f.typ)
+}
+
+// checkParams returns an error if h was made by WithParams for a function
+// that doesn't take one argument per parameter of path. Other handlers
+// aren't called.
+func checkParams(path string, h HandlerFunc) error {
+	if reflect.ValueOf(h).Pointer() != withParamsCode {
+		return nil
+	}
+	f, ok := h(paramsProbe).(paramsFunc)
+	if !ok {
+		return nil
+	}
+	names := paramNames(path)
+	if n := f.typ.NumIn(); n != len(names) {
+		return fmt.Errorf("WithParams: route %s has %d parameters %v but handler %s takes %d", path, len(names), names, f.typ, n)
+	}
+	return nil
+}
+
+// paramNames returns the parameter names of a route pattern in order
+func paramNames(pattern string) []string {
+	var names []string
+	for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			// Drop an optional marker and a constraint such as :id(int)
+			name := strings.TrimSuffix(segment[1:], "?")
+			if i := strings.IndexByte(name, '('); i >= 0 {
+				name = name[:i]
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,90 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithParams(t *testing.T) {
+	r := New()
+
+	r.Get("/users/:id", WithParams(func(id string) (interface{}, error) {
+		return map[string]string{"id": id}, nil
+	}))
+	r.Get("/users/:user_id/posts/:post_id", WithParams(func(userID, postID string) (interface{}, error) {
+		return []string{userID, postID}, nil
+	}))
+	r.Get("/missing/:id", WithParams(func(id string) (map[string]string, error) {
+		return nil, NotFound("no such thing: " + id)
+	}))
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/users/42", http.StatusOK, `{"id":"42"}`},
+		{"/users/7/posts/9", http.StatusOK, `["7","9"]`},
+		{"/missing/x", http.StatusNotFound, `{"error":"no such thing: x"}`},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+		if w.Code != tt.code {
+			t.Errorf("GET %s: expected status %d, got %d", tt.path, tt.code, w.Code)
+		}
+		if strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("GET %s: expected body %s, got %s", tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestWithParamsInvalidFunction(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+	}{
+		{"not a function", "nope"},
+		{"non-string argument", func(id int) (interface{}, error) { return nil, nil }},
+		{"missing error result", func(id string) interface{} { return nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if rec := recover(); rec == nil {
+					t.Error("expected panic")
+				}
+			}()
+			WithParams(tt.fn)
+		})
+	}
+}
+
+func TestWithParamsArityMismatch(t *testing.T) {
+	r := New()
+	handler := WithParams(func(id string) (interface{}, error) {
+		return nil, nil
+	})
+
+	if err := r.TryGet("/users/:id/posts/:post_id", handler); err == nil || !strings.Contains(err.Error(), "has 2 parameters") {
+		t.Errorf("expected an arity error, got %v", err)
+	}
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected registration to panic for arity mismatch")
+		}
+		if msg := fmt.Sprint(rec); !strings.Contains(msg, "has 2 parameters") {
+			t.Errorf("unexpected panic message: %s", msg)
+		}
+	}()
+
+	r.Get("/users/:id/posts/:post_id", handler)
+}
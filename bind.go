@@ -0,0 +1,215 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BindParams populates the fields of the struct obj points to from the
+// route parameters, using the field's `param` tag as the parameter name:
+//
+//	type ShowRequest struct {
+//	    ID   int    `param:"id"`
+//	    Slug string `param:"slug"`
+//	}
+//
+// Fields may be strings, bools, or signed or unsigned integers and floats of
+// any size. Untagged fields, and fields whose parameter isn't present, are
+// left unchanged. A value that can't be converted returns a 400 StatusError.
+func (c *Context) BindParams(obj interface{}) error {
+	return bindTagged(obj, "param", func(name string) ([]string, bool) {
+		value, ok := c.Params[name]
+		return []string{value}, ok
+	})
+}
+
+// BindAll populates a struct from the JSON body, the query string and the
+// route parameters in one call, so a request type can declare where each
+// field comes from:
+//
+//	type UpdateRequest struct {
+//	    ID     int    `param:"id"`
+//	    DryRun bool   `query:"dry_run"`
+//	    Name   string `json:"name"`
+//	}
+//
+// The sources are applied in that order, so a field tagged for more than one
+// source takes the value of the last one present: route parameters win over
+// the query string, which wins over the body. An empty body is skipped.
+func (c *Context) BindAll(obj interface{}) error {
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	if err := c.BindQuery(obj); err != nil {
+		return err
+	}
+	return c.BindParams(obj)
+}
+
+// BindForm populates the fields of the struct obj points to from a
+// urlencoded or multipart form body, using the field's `form` tag as the
+// field name:
+//
+//	type SignupRequest struct {
+//	    Email string   `form:"email"`
+//	    Tags  []string `form:"tags"`
+//	}
+//
+// Fields are converted as in BindQuery. Uploaded files are left to
+// FormFile.
+func (c *Context) BindForm(obj interface{}) error {
+	if err := c.parseForm(); err != nil {
+		return NewStatusError(http.StatusBadRequest, "invalid form body")
+	}
+	return bindTagged(obj, "form", func(name string) ([]string, bool) {
+		values, ok := c.Request.PostForm[name]
+		return values, ok
+	})
+}
+
+// Bind decodes the request body into obj with the decoder for its
+// Content-Type: BindJSON for application/json, BindXML for application/xml
+// and text/xml, and BindForm for application/x-www-form-urlencoded and
+// multipart/form-data. Other content types, or a missing one, return a
+// 415 Unsupported Media Type StatusError.
+func (c *Context) Bind(obj interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		return c.BindJSON(obj)
+	case "application/xml", "text/xml":
+		return c.BindXML(obj)
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return c.BindForm(obj)
+	}
+	return NewStatusError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", c.Request.Header.Get("Content-Type")))
+}
+
+// Validator is implemented by request types that can check themselves once
+// bound, as used by BindAndValidate. Validate may return a StatusError to
+// choose the status of the error response.
+type Validator interface {
+	Validate() error
+}
+
+// BindAndValidate binds a JSON request body like BindJSON and then, if obj
+// implements Validator, validates it, returning the first error from
+// either step:
+//
+//	type CreateUserRequest struct {
+//	    Name string `json:"name"`
+//	}
+//
+//	func (r *CreateUserRequest) Validate() error {
+//	    if r.Name == "" {
+//	        return router.NewStatusError(http.StatusUnprocessableEntity, "name is required")
+//	    }
+//	    return nil
+//	}
+//
+// Validation is left to Validate, so any validation library can be used.
+func (c *Context) BindAndValidate(obj interface{}) error {
+	if err := c.BindJSON(obj); err != nil {
+		return err
+	}
+	if v, ok := obj.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// bindTagged sets each field of the struct obj points to that has a tag
+// named tag, from the values lookup returns for the tag's name. Slice fields
+// take every value; other fields take the first.
+func bindTagged(obj interface{}, tag string, lookup func(name string) ([]string, bool)) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to a struct, got %T", obj)
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values, ok := lookup(name)
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := setField(v.Field(i), values); err != nil {
+			return &StatusError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid %s %q: %v", tag, name, err),
+				Err:     err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// setField converts values into the field's type and stores them
+func setField(f reflect.Value, values []string) error {
+	if f.Kind() != reflect.Slice {
+		return setValue(f, values[0])
+	}
+
+	slice := reflect.MakeSlice(f.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := setValue(slice.Index(i), value); err != nil {
+			return err
+		}
+	}
+	f.Set(slice)
+	return nil
+}
+
+// setValue converts a single value into the field's type and stores it
+func setValue(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a bool", value)
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, f.Type())
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, f.Type())
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, f.Type())
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
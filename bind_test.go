@@ -0,0 +1,211 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindParams(t *testing.T) {
+	type showRequest struct {
+		UserID  int64  `param:"user_id"`
+		Slug    string `param:"slug"`
+		Ignored string
+	}
+
+	c := &Context{Params: Params{"user_id": "42", "slug": "hello"}}
+
+	var got showRequest
+	if err := c.BindParams(&got); err != nil {
+		t.Fatalf("BindParams failed: %v", err)
+	}
+	if got.UserID != 42 || got.Slug != "hello" || got.Ignored != "" {
+		t.Errorf("unexpected result %+v", got)
+	}
+
+	c.Params["user_id"] = "abc"
+	err := c.BindParams(&got)
+
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusBadRequest {
+		t.Fatalf("expected a 400 StatusError, got %v", err)
+	}
+	if !strings.Contains(se.Message, "user_id") {
+		t.Errorf("expected the error to name the parameter, got %q", se.Message)
+	}
+
+	if err := c.BindParams(got); err == nil {
+		t.Error("expected an error for a non-pointer target")
+	}
+}
+
+func TestBindAll(t *testing.T) {
+	type updateRequest struct {
+		ID     int      `param:"id"`
+		DryRun bool     `query:"dry_run"`
+		Tags   []string `query:"tag"`
+		Name   string   `json:"name"`
+		Source string   `json:"source" query:"source" param:"source"`
+	}
+
+	r := New()
+
+	var got updateRequest
+	var bindErr error
+	r.Put("/items/:id", func(c *Context) error {
+		got = updateRequest{}
+		bindErr = c.BindAll(&got)
+		return nil
+	})
+	r.Put("/sources/:source", func(c *Context) error {
+		got = updateRequest{}
+		bindErr = c.BindAll(&got)
+		return nil
+	})
+
+	req := httptest.NewRequest("PUT", "/items/7?dry_run=true&tag=a&tag=b&source=query", strings.NewReader(`{"name":"widget","source":"body"}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if bindErr != nil {
+		t.Fatalf("BindAll failed: %v", bindErr)
+	}
+	if got.ID != 7 || !got.DryRun || got.Name != "widget" || len(got.Tags) != 2 || got.Tags[1] != "b" {
+		t.Errorf("unexpected result %+v", got)
+	}
+	if got.Source != "query" {
+		t.Errorf("expected the query string to win over the body, got %q", got.Source)
+	}
+
+	req = httptest.NewRequest("PUT", "/sources/param?source=query", strings.NewReader(`{"source":"body"}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Source != "param" {
+		t.Errorf("expected route params to win, got %q", got.Source)
+	}
+
+	// An empty body is not an error
+	req = httptest.NewRequest("PUT", "/items/8", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if bindErr != nil || got.ID != 8 {
+		t.Errorf("expected binding without a body to succeed, got %+v (%v)", got, bindErr)
+	}
+}
+
+// createUserRequest rejects an empty name
+type createUserRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *createUserRequest) Validate() error {
+	if r.Name == "" {
+		return NewStatusError(http.StatusUnprocessableEntity, "name is required")
+	}
+	return nil
+}
+
+func TestBindAndValidate(t *testing.T) {
+	r := New()
+	r.Post("/users", func(c *Context) error {
+		var req createUserRequest
+		if err := c.BindAndValidate(&req); err != nil {
+			return err
+		}
+		return c.String(http.StatusCreated, req.Name)
+	})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"name":"ada"}`, http.StatusCreated},
+		{"empty name", `{"name":""}`, http.StatusUnprocessableEntity},
+		{"invalid json", `{"name":`, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindAndValidateWithoutValidator(t *testing.T) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"name":""}`)))
+	if err := c.BindAndValidate(&req); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	type signupRequest struct {
+		Name string   `json:"name" xml:"name" form:"name"`
+		Age  int      `json:"age" xml:"age" form:"age"`
+		Tags []string `json:"tags" xml:"tag" form:"tags"`
+	}
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("name", "ada")
+	mw.WriteField("age", "36")
+	mw.WriteField("tags", "math")
+	mw.WriteField("tags", "engines")
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json; charset=utf-8", `{"name":"ada","age":36,"tags":["math","engines"]}`},
+		{"xml", "application/xml", `<signup><name>ada</name><age>36</age><tag>math</tag><tag>engines</tag></signup>`},
+		{"text xml", "text/xml", `<signup><name>ada</name><age>36</age><tag>math</tag><tag>engines</tag></signup>`},
+		{"form", "application/x-www-form-urlencoded", "name=ada&age=36&tags=math&tags=engines"},
+		{"multipart", mw.FormDataContentType(), multipartBody.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/?name=query", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			c := newContext(httptest.NewRecorder(), req)
+
+			var got signupRequest
+			if err := c.Bind(&got); err != nil {
+				t.Fatalf("Bind failed: %v", err)
+			}
+			if got.Name != "ada" || got.Age != 36 || strings.Join(got.Tags, ",") != "math,engines" {
+				t.Errorf("unexpected result: %+v", got)
+			}
+		})
+	}
+}
+
+func TestBindUnsupportedContentType(t *testing.T) {
+	for _, contentType := range []string{"text/plain", ""} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=ada"))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		var got struct{}
+		err := newContext(httptest.NewRecorder(), req).Bind(&got)
+		var se *StatusError
+		if !errors.As(err, &se) || se.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("%q: expected a 415 StatusError, got %v", contentType, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+)
+
+// bufferResponse points c.Writer at a bufferWriter that holds the response
+// back until it is committed (see Router.BufferResponses)
+func bufferResponse(c *Context) *bufferWriter {
+	bw := &bufferWriter{dst: c.Writer, header: c.Writer.Header().Clone()}
+	bw.src = &responseWriter{ResponseWriter: bw, status: c.Writer.status}
+	c.Writer = bw.src
+	return bw
+}
+
+// bufferWriter buffers a response in memory. Committing it sends the
+// buffered headers, status and body to dst; a response that is never
+// committed is discarded, leaving dst as it was.
+type bufferWriter struct {
+	dst         *responseWriter
+	src         *responseWriter // the handler's writer, with any SetStatus status
+	header      http.Header
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	committed   bool // sent to dst; later writes go straight through
+}
+
+// Header returns the buffered headers, or dst's once committed
+func (w *bufferWriter) Header() http.Header {
+	if w.committed {
+		return w.dst.Header()
+	}
+	return w.header
+}
+
+// WriteHeader records the status code
+func (w *bufferWriter) WriteHeader(code int) {
+	if w.committed {
+		w.dst.WriteHeader(code)
+		return
+	}
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+}
+
+// Write buffers the response body
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	if w.committed {
+		return w.dst.Write(b)
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush commits the response, so that streaming handlers reach the client.
+// Errors returned after a flush can no longer replace the response.
+func (w *bufferWriter) Flush() {
+	w.commit()
+	w.dst.Flush()
+}
+
+// Hijack commits the response and passes through to dst
+func (w *bufferWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.commit()
+	return w.dst.Hijack()
+}
+
+// commit sends the buffered response to dst
+func (w *bufferWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	dst := w.dst.Header()
+	for k := range dst {
+		if _, ok := w.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range w.header {
+		dst[k] = v
+	}
+
+	if w.wroteHeader {
+		w.dst.WriteHeader(w.status)
+	} else if w.src.status != http.StatusOK {
+		w.dst.WriteHeader(w.src.status)
+	}
+	if w.buf.Len() > 0 {
+		w.dst.Write(w.buf.Bytes())
+	}
+}
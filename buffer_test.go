@@ -0,0 +1,94 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferResponses(t *testing.T) {
+	r := New()
+	r.BufferResponses = true
+	r.Get("/report", func(c *Context) error {
+		c.SetHeader("Content-Disposition", "attachment")
+		c.String(http.StatusOK, "partial report")
+		return errors.New("database unavailable")
+	})
+	r.Get("/ok", func(c *Context) error {
+		c.SetHeader("X-Total", "3")
+		return c.String(http.StatusCreated, "done")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, "partial") || !strings.Contains(body, `"error"`) {
+		t.Errorf("expected only the error JSON, got %q", body)
+	}
+	if w.Header().Get("Content-Disposition") != "" {
+		t.Error("expected the failed handler's headers to be discarded")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Total") != "3" {
+		t.Errorf("expected the buffered response, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestBufferResponsesOff(t *testing.T) {
+	r := New()
+	r.Get("/report", func(c *Context) error {
+		c.String(http.StatusOK, "partial report")
+		return errors.New("database unavailable")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "partial report") {
+		t.Errorf("expected the partial response without buffering, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestBufferResponsesStreaming(t *testing.T) {
+	r := New()
+	r.BufferResponses = true
+	r.Get("/events", func(c *Context) error {
+		n := 0
+		err := c.Stream(http.StatusOK, "text/event-stream", func(w io.Writer) bool {
+			n++
+			io.WriteString(w, "data: tick\n\n")
+			return n < 2
+		})
+		if err != nil {
+			return err
+		}
+		return errors.New("too late")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if !w.Flushed {
+		t.Error("expected the stream to be flushed to the client")
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "data: tick\n\ndata: tick\n\n" {
+		t.Errorf("expected the streamed events, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestBufferResponsesKeepsEarlierHeaders(t *testing.T) {
+	r := New()
+	r.BufferResponses = true
+	r.Post("/users", func(c *Context) error { return nil })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") == "" {
+		t.Errorf("expected a 405 with an Allow header, got %d %v", w.Code, w.Header())
+	}
+}
@@ -1,10 +1,20 @@
 package router
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
 )
 
 // responseWriter wraps http.ResponseWriter to track response state
@@ -12,6 +22,7 @@ type responseWriter struct {
 	http.ResponseWriter
 	status      int
 	wroteHeader bool
+	size        int // body bytes written
 }
 
 // WriteHeader captures the status code and tracks that headers were written
@@ -23,12 +34,15 @@ func (w *responseWriter) WriteHeader(code int) {
 	}
 }
 
-// Write ensures WriteHeader is called and tracks that response started
+// Write ensures WriteHeader is called, with the status set by SetStatus or
+// 200, and tracks that response started
 func (w *responseWriter) Write(b []byte) (int, error) {
 	if !w.wroteHeader {
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(w.status)
 	}
-	return w.ResponseWriter.Write(b)
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
 }
 
 // Status returns the HTTP status code that was written
@@ -36,6 +50,41 @@ func (w *responseWriter) Status() int {
 	return w.status
 }
 
+// Flush sends any buffered response data to the client, writing the
+// pending status first if none was written. It does nothing if the
+// underlying writer doesn't implement http.Flusher.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(w.status)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, as WebSocket libraries
+// do. It returns an error if the underlying writer doesn't implement
+// http.Hijacker. After a successful hijack the response counts as written,
+// so the ErrorHandler won't try to write to the connection.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking", w.ResponseWriter)
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter so that
+// http.ResponseController can reach optional interfaces like http.Flusher
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Context provides a convenient interface for handling HTTP requests and responses.
 // It wraps http.ResponseWriter and *http.Request with helper methods for common tasks
 // like sending JSON, parsing parameters, setting headers, and storing request-scoped values.
@@ -67,23 +116,61 @@ func (w *responseWriter) Status() int {
 //	    // Send JSON response
 //	    return c.JSON(200, map[string]string{"id": id})
 //	}
+//
+// Contexts are pooled and reused for later requests, along with their
+// Params map. A Context must not be used once its handler has returned,
+// for example from a goroutine the handler started: copy the values it
+// needs (c.Param("id"), c.Request.Context()) before the handler returns.
 type Context struct {
-	Writer  *responseWriter
+	Writer *responseWriter
+
+	// Request is the request being handled. Every Context helper reads
+	// through this field, so middleware may assign a derived request
+	// (e.g. with a new context.Context) and downstream handlers will see it.
+	// See SetRequest.
 	Request *http.Request
-	Params  Params
-	store   map[string]interface{}
-	index   int // for middleware chain
+
+	Params Params
+
+	store      map[string]interface{}
+	sensitive  map[string]bool // store keys redacted by StoreSnapshot
+	index      int             // for middleware chain
+	router     *Router         // router serving the request
+	pattern    string          // pattern of the matched route
+	formParsed bool            // whether parseForm has run
+	formErr    error           // result of parseForm
+	writer     responseWriter  // Writer's target, reused with the Context
+	retained   bool            // still in use after the request; not pooled
 }
 
 // newContext creates a new Context instance
 func newContext(w http.ResponseWriter, r *http.Request) *Context {
-	return &Context{
-		Writer:  &responseWriter{ResponseWriter: w, status: http.StatusOK},
+	c := &Context{}
+	c.reset(w, r)
+	return c
+}
+
+// reset prepares a Context for a new request, keeping the maps of the
+// previous one (emptied) to save allocating them again
+func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
+	params, store := c.Params, c.store
+	if params == nil {
+		params = make(Params)
+	}
+	if store == nil {
+		store = make(map[string]interface{})
+	}
+	clear(params)
+	clear(store)
+
+	*c = Context{
 		Request: r,
-		Params:  make(Params),
-		store:   make(map[string]interface{}),
+		Params:  params,
+		store:   store,
 		index:   -1,
+		writer:  responseWriter{ResponseWriter: w, status: http.StatusOK},
 	}
+	c.Writer = &c.writer
 }
 
 // IsHeaderWritten returns true if response headers have been sent to the client.
@@ -103,11 +190,125 @@ func (c *Context) IsHeaderWritten() bool {
 	return c.Writer.wroteHeader
 }
 
-// Param returns a route parameter by name
+// SetRequest replaces the request being handled.
+// Middleware uses this to pass a derived request down the chain, typically
+// one carrying a new context.Context:
+//
+//	func withTenant(next router.HandlerFunc) router.HandlerFunc {
+//	    return func(c *router.Context) error {
+//	        ctx := context.WithValue(c.Request.Context(), tenantKey, c.Header("X-Tenant"))
+//	        c.SetRequest(c.Request.WithContext(ctx))
+//	        return next(c)
+//	    }
+//	}
+//
+// The replacement is visible to all later middleware, the handler, and the
+// ErrorHandler. Passing nil is a no-op.
+func (c *Context) SetRequest(req *http.Request) {
+	if req != nil {
+		c.Request = req
+	}
+}
+
+// Context returns the request's context.Context. It is cancelled when the
+// client disconnects or, for routes using WithTimeout, when time runs out.
+func (c *Context) Context() context.Context {
+	return c.Request.Context()
+}
+
+// WithValue replaces the request with one whose context carries val for
+// key, so it can be read downstream with c.Context().Value(key) or by any
+// code given the request's context. Unlike Set, the value travels with the
+// standard context.Context, e.g. into database or HTTP client calls.
+func (c *Context) WithValue(key, val interface{}) {
+	c.SetRequest(c.Request.WithContext(context.WithValue(c.Request.Context(), key, val)))
+}
+
+// Param returns a route parameter by name, or "" if the route has no such
+// parameter. It is safe to call when Params is nil.
 func (c *Context) Param(name string) string {
 	return c.Params[name]
 }
 
+// MustParam returns a route parameter by name like Param, but panics if
+// the matched route doesn't declare the parameter, so that a typo such as
+// c.MustParam("userid") for a route /users/:user_id fails loudly instead
+// of reading "". An optional parameter missing from the request is
+// declared, and returns "".
+//
+// The panic is a programmer error; it reaches whatever recovers panics in
+// the server, such as recovery middleware or net/http itself.
+func (c *Context) MustParam(name string) string {
+	if value, ok := c.Params[name]; ok {
+		return value
+	}
+	for _, declared := range paramNames(c.pattern) {
+		if declared == name {
+			return ""
+		}
+	}
+	panic(fmt.Sprintf("route %q has no parameter %q", c.pattern, name))
+}
+
+// ParamInt returns a route parameter parsed as an int.
+// It returns an error wrapping ErrParamNotFound if the route has no such
+// parameter, and a 400 StatusError if the value isn't an integer, so a
+// handler can return the error as-is:
+//
+//	id, err := c.ParamInt("id")
+//	if err != nil {
+//	    return err
+//	}
+func (c *Context) ParamInt(name string) (int, error) {
+	n, err := c.paramInt(name, strconv.IntSize)
+	return int(n), err
+}
+
+// ParamInt64 returns a route parameter parsed as an int64.
+// Errors are reported as for ParamInt.
+func (c *Context) ParamInt64(name string) (int64, error) {
+	return c.paramInt(name, 64)
+}
+
+// paramInt parses a route parameter as an integer of the given bit size
+func (c *Context) paramInt(name string, bitSize int) (int64, error) {
+	value, ok := c.Params[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrParamNotFound, name)
+	}
+
+	n, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return 0, &StatusError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("parameter %q must be an integer, got %q", name, value),
+			Err:     err,
+		}
+	}
+	return n, nil
+}
+
+// WildcardPath returns a *wildcard parameter as a cleaned, relative,
+// slash-separated path that is safe to join onto a directory:
+// duplicate slashes and "." elements are removed, ".." elements can't climb
+// above the root, and leading or trailing slashes are stripped.
+// Backslashes are treated as separators. The result is "" for the root.
+//
+//	r.Get("/files/*filepath", func(c *Context) error {
+//	    rel := c.WildcardPath("filepath") // "/files/a//b/../c/" -> "a/c"
+//	    f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+//	    ...
+//	})
+func (c *Context) WildcardPath(param string) string {
+	return cleanWildcardPath(c.Param(param))
+}
+
+// cleanWildcardPath cleans a wildcard capture (see Context.WildcardPath)
+func cleanWildcardPath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
 // Query returns a URL query parameter by name.
 // Returns (value, true) if the parameter exists, or ("", false) if it doesn't.
 func (c *Context) Query(name string) (string, bool) {
@@ -131,6 +332,40 @@ func (c *Context) Set(key string, value interface{}) {
 	c.store[key] = value
 }
 
+// SetSensitive stores a value in the context and marks its key as sensitive.
+// Sensitive values are available through Get like any other, but are
+// redacted by StoreSnapshot so they don't leak into logs or debug output:
+//
+//	c.SetSensitive("api_token", token)
+//	log.Printf("store: %v", c.StoreSnapshot()) // map[api_token:[REDACTED] ...]
+//
+// A key stays sensitive for the rest of the request, even if it's later
+// overwritten with Set.
+func (c *Context) SetSensitive(key string, value interface{}) {
+	if c.sensitive == nil {
+		c.sensitive = make(map[string]bool)
+	}
+	c.sensitive[key] = true
+	c.Set(key, value)
+}
+
+// StoreSnapshot returns a copy of the values stored in the context, with
+// values set by SetSensitive replaced by "[REDACTED]". It's intended for
+// logging and debugging; use Get to read actual values.
+func (c *Context) StoreSnapshot() map[string]interface{} {
+	snapshot := make(map[string]interface{}, len(c.store))
+	for key, value := range c.store {
+		if c.sensitive[key] {
+			value = redacted
+		}
+		snapshot[key] = value
+	}
+	return snapshot
+}
+
+// redacted replaces sensitive values in StoreSnapshot
+const redacted = "[REDACTED]"
+
 // Get retrieves a value from the context.
 // Returns (value, true) if the key exists, or (nil, false) if it doesn't.
 func (c *Context) Get(key string) (interface{}, bool) {
@@ -159,11 +394,40 @@ func (c *Context) GetBool(key string) (bool, bool) {
 	return val, ok
 }
 
-// JSON sends a JSON response
+// JSON sends a JSON response, encoded with Router.JSONEncoder if set
 func (c *Context) JSON(status int, data interface{}) error {
 	c.Writer.Header().Set("Content-Type", "application/json")
 	c.Writer.WriteHeader(status)
-	return json.NewEncoder(c.Writer).Encode(data)
+	return c.jsonEncoder().Encode(data)
+}
+
+// JSONPretty sends a JSON response indented with two spaces, for
+// debugging endpoints and responses meant to be read by people
+func (c *Context) JSONPretty(status int, data interface{}) error {
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(status)
+	enc := c.jsonEncoder()
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// jsonEncoder returns an encoder writing to the response, created by
+// Router.JSONEncoder if set
+func (c *Context) jsonEncoder() *json.Encoder {
+	if c.router != nil && c.router.JSONEncoder != nil {
+		return c.router.JSONEncoder(c.Writer)
+	}
+	return json.NewEncoder(c.Writer)
+}
+
+// XML sends an XML response, preceded by the standard XML header
+func (c *Context) XML(status int, data interface{}) error {
+	c.Writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.Writer.WriteHeader(status)
+	if _, err := io.WriteString(c.Writer, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(c.Writer).Encode(data)
 }
 
 // String sends a plain text response
@@ -205,6 +469,231 @@ func (c *Context) Redirect(status int, url string) error {
 	return nil
 }
 
+// File sends the file at path, with a Content-Type from its extension and
+// support for Range and If-Modified-Since requests, as with http.ServeContent.
+// A missing file, or a directory, returns a 404 StatusError wrapping the
+// cause, so the ErrorHandler can respond.
+func (c *Context) File(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return &StatusError{Code: http.StatusNotFound, Message: "Not Found", Err: err}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return &StatusError{Code: http.StatusNotFound, Message: "Not Found", Err: fmt.Errorf("%s is a directory", path)}
+	}
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+	return nil
+}
+
+// Attachment sends the file at path like File, with a Content-Disposition
+// header so browsers download it as filename rather than display it.
+func (c *Context) Attachment(path, filename string) error {
+	c.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	if err := c.File(path); err != nil {
+		// Don't send the error response as a download
+		c.Writer.Header().Del("Content-Disposition")
+		return err
+	}
+	return nil
+}
+
+// ETag sets the ETag response header and reports whether the request's
+// If-None-Match already has it, in which case it has responded with 304
+// Not Modified and the handler should return without a body:
+//
+//	r.Get("/users/:id", func(c *Context) error {
+//	    user := findUser(c.Param("id"))
+//	    if c.ETag(user.Version) {
+//	        return nil
+//	    }
+//	    return c.JSON(200, user)
+//	})
+//
+// The tag is quoted if it isn't already; weak tags such as W/"v1" are
+// kept as they are. Tags are compared with the weak comparison If-None-Match
+// calls for, so a weak and a strong tag with the same value match. Only
+// GET and HEAD requests are answered with 304.
+func (c *Context) ETag(tag string) bool {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) {
+		tag = `"` + tag + `"`
+	}
+	c.SetHeader("ETag", tag)
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	if !etagMatches(c.Request.Header.Get("If-None-Match"), tag) {
+		return false
+	}
+
+	c.NoContent(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists tag, using
+// weak comparison
+func etagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Stream writes a response incrementally, for server-sent events or long
+// downloads. It writes the status and Content-Type, then calls step
+// repeatedly, flushing what it wrote to the client after each call, until
+// step returns false or the client disconnects:
+//
+//	return c.Stream(200, "text/event-stream", func(w io.Writer) bool {
+//	    event, ok := <-events
+//	    if !ok {
+//	        return false
+//	    }
+//	    fmt.Fprintf(w, "data: %s\n\n", event)
+//	    return true
+//	})
+//
+// A disconnected client ends the stream without an error, since there is
+// no one left to report it to.
+func (c *Context) Stream(status int, contentType string, step func(w io.Writer) bool) error {
+	c.SetHeader("Content-Type", contentType)
+	c.Writer.WriteHeader(status)
+	c.Writer.Flush() // send the headers before the first step
+
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		more := step(c.Writer)
+		c.Writer.Flush()
+		if !more {
+			return nil
+		}
+	}
+}
+
+// JSONStream sends a JSON array whose elements arrive on items, without
+// holding the whole array in memory. It writes "[", each item encoded as
+// by JSON as it arrives, separated by commas, and "]" once items is
+// closed. What has been written is flushed to the client whenever the
+// next item isn't ready yet:
+//
+//	items := make(chan interface{})
+//	go func() {
+//	    defer close(items)
+//	    for rows.Next() {
+//	        select {
+//	        case items <- scanRow(rows):
+//	        case <-c.Request.Context().Done():
+//	            return
+//	        }
+//	    }
+//	}()
+//	return c.JSONStream(http.StatusOK, items)
+//
+// If the request context is cancelled or its deadline passes, JSONStream
+// stops and returns the context's error, leaving the array unfinished; the
+// goroutine sending items should watch the context too, as above, so it
+// doesn't block forever. An item that can't be encoded also stops the
+// stream, with the encoding error.
+func (c *Context) JSONStream(status int, items <-chan interface{}) error {
+	c.SetHeader("Content-Type", "application/json")
+	c.Writer.WriteHeader(status)
+	if _, err := io.WriteString(c.Writer, "["); err != nil {
+		return err
+	}
+
+	enc := c.jsonEncoder()
+	ctx := c.Request.Context()
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var item interface{}
+		var ok bool
+		select {
+		case item, ok = <-items:
+		default:
+			// Send what's written so far while waiting
+			c.Writer.Flush()
+			select {
+			case item, ok = <-items:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if !ok {
+			break
+		}
+
+		if n > 0 {
+			if _, err := io.WriteString(c.Writer, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(c.Writer, "]")
+	return err
+}
+
+// Created responds with 201 Created and a Location header pointing at the
+// named route, with its parameters filled from params in order:
+//
+//	r.Post("/users", func(c *Context) error {
+//	    user := createUser(c)
+//	    return c.Created("users_show", user.ID) // Location: /users/42
+//	})
+func (c *Context) Created(name string, params ...string) error {
+	location, err := c.urlFor(name, params...)
+	if err != nil {
+		return err
+	}
+	c.SetHeader("Location", location)
+	return c.NoContent(http.StatusCreated)
+}
+
+// SeeOtherRoute redirects with 303 See Other to the named route, with its
+// parameters filled from params in order. It completes the
+// POST-redirect-GET pattern for form submissions.
+func (c *Context) SeeOtherRoute(name string, params ...string) error {
+	location, err := c.urlFor(name, params...)
+	if err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusSeeOther, location)
+}
+
+// urlFor builds the URL of a named route of the router serving the request
+func (c *Context) urlFor(name string, params ...string) (string, error) {
+	if c.router == nil {
+		return "", fmt.Errorf("cannot build the URL for route %q: the Context has no router", name)
+	}
+	return c.router.URLFor(name, params...)
+}
+
 // BindJSON binds JSON request body to a struct
 func (c *Context) BindJSON(obj interface{}) error {
 	if c.Request.Body == nil {
@@ -214,6 +703,87 @@ func (c *Context) BindJSON(obj interface{}) error {
 	return decoder.Decode(obj)
 }
 
+// BindXML binds XML request body to a struct
+func (c *Context) BindXML(obj interface{}) error {
+	if c.Request.Body == nil {
+		return fmt.Errorf("request body is empty")
+	}
+	decoder := xml.NewDecoder(c.Request.Body)
+	return decoder.Decode(obj)
+}
+
+// BindJSONStrict binds a JSON request body to a struct like BindJSON, but
+// returns an error naming the first key that doesn't match a field of obj,
+// instead of ignoring it
+func (c *Context) BindJSONStrict(obj interface{}) error {
+	if c.Request.Body == nil {
+		return fmt.Errorf("request body is empty")
+	}
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(obj)
+}
+
+// BindQuery populates the fields of the struct obj points to from the query
+// string, using the field's `query` tag as the parameter name:
+//
+//	type SearchRequest struct {
+//	    Q    string   `query:"q"`
+//	    Page int      `query:"page"`
+//	    Tags []string `query:"tags"`
+//	}
+//
+// Slice fields receive every value of a repeated parameter; other fields
+// receive the first. Supported types and errors are as for BindParams.
+func (c *Context) BindQuery(obj interface{}) error {
+	query := c.Request.URL.Query()
+	return bindTagged(obj, "query", func(name string) ([]string, bool) {
+		values, ok := query[name]
+		return values, ok
+	})
+}
+
+// FormValue returns the first value of a form field from a urlencoded or
+// multipart body, or from the query string, as http.Request.FormValue does.
+// It returns "" if the field is absent or the form can't be parsed.
+func (c *Context) FormValue(name string) string {
+	c.parseForm()
+	return c.Request.FormValue(name)
+}
+
+// FormFile returns the first file uploaded in a multipart form field.
+// Files beyond Router.MaxMultipartMemory are stored on disk temporarily.
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if err := c.parseForm(); err != nil {
+		return nil, err
+	}
+	if c.Request.MultipartForm == nil || len(c.Request.MultipartForm.File[name]) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return c.Request.MultipartForm.File[name][0], nil
+}
+
+// parseForm parses the request body as a form on first use and caches the
+// result, so the body is read at most once
+func (c *Context) parseForm() error {
+	if c.formParsed {
+		return c.formErr
+	}
+	c.formParsed = true
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		maxMemory := int64(defaultMaxMultipartMemory)
+		if c.router != nil && c.router.MaxMultipartMemory > 0 {
+			maxMemory = c.router.MaxMultipartMemory
+		}
+		c.formErr = c.Request.ParseMultipartForm(maxMemory)
+	} else {
+		c.formErr = c.Request.ParseForm()
+	}
+	return c.formErr
+}
+
 // Body returns the request body as bytes
 func (c *Context) Body() ([]byte, error) {
 	return io.ReadAll(c.Request.Body)
@@ -254,22 +824,134 @@ func (c *Context) Status(code int) {
 	c.Writer.WriteHeader(code)
 }
 
-// GetStatus returns the HTTP status code that was written (or will be written).
-// Returns 200 if no status has been explicitly set.
+// SetStatus sets the status the response will be sent with, without
+// writing the headers as Status does. A later body write uses it, while a
+// helper given its own status, such as JSON or NoContent, replaces it. This
+// lets middleware pick a default status that handlers can still override:
+//
+//	func accepted(next router.HandlerFunc) router.HandlerFunc {
+//	    return func(c *router.Context) error {
+//	        c.SetStatus(http.StatusAccepted)
+//	        return next(c)
+//	    }
+//	}
+//
+// If the handler returns nil without writing anything, the response is
+// sent with the status set here. It does nothing once the headers have
+// been written.
+func (c *Context) SetStatus(code int) {
+	if !c.Writer.wroteHeader {
+		c.Writer.status = code
+	}
+}
+
+// GetStatus returns the HTTP status code that was written (or will be written,
+// as set by SetStatus). Returns 200 if no status has been explicitly set.
 func (c *Context) GetStatus() int {
 	return c.Writer.Status()
 }
 
-// ClientIP returns the client's IP address
+// BytesWritten returns the number of response body bytes written so far,
+// as reported in access logs. Compression by Gzip happens after they are
+// counted, so it is the uncompressed size.
+func (c *Context) BytesWritten() int {
+	return c.Writer.size
+}
+
+// ClientIP returns the client's IP address. It is taken from the
+// left-most X-Forwarded-For entry, or else from X-Real-IP, or else from
+// the request's RemoteAddr.
+//
+// Headers are set by whoever sends the request, so when the router is
+// behind proxies set Router.TrustedProxies: the headers are then only used
+// on requests from a trusted proxy, and the client is the right-most
+// X-Forwarded-For entry that isn't a trusted proxy.
 func (c *Context) ClientIP() string {
+	var proxies []*net.IPNet
+	if c.router != nil && len(c.router.TrustedProxies) > 0 {
+		proxies = c.router.trustedProxies()
+		if !isTrustedProxy(remoteIP(c.Request.RemoteAddr), proxies) {
+			return c.Request.RemoteAddr
+		}
+	}
+
 	// Check X-Forwarded-For header first
-	if ip := c.Request.Header.Get("X-Forwarded-For"); ip != "" {
-		return ip
+	if hops := forwardedFor(c.Request.Header); len(hops) > 0 {
+		if proxies == nil {
+			return hops[0]
+		}
+		// Walk back from the nearest hop past the trusted proxies
+		for i := len(hops) - 1; i > 0; i-- {
+			if !isTrustedProxy(net.ParseIP(hops[i]), proxies) {
+				return hops[i]
+			}
+		}
+		return hops[0]
 	}
 	// Check X-Real-IP header
-	if ip := c.Request.Header.Get("X-Real-IP"); ip != "" {
+	if ip := strings.TrimSpace(c.Request.Header.Get("X-Real-IP")); ip != "" {
 		return ip
 	}
 	// Fall back to RemoteAddr
 	return c.Request.RemoteAddr
 }
+
+// forwardedFor returns the non-empty entries of the X-Forwarded-For
+// headers, from the client to the nearest proxy
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// remoteIP parses the IP address of a RemoteAddr, with or without a port
+func remoteIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// parseTrustedProxies parses Router.TrustedProxies, skipping invalid entries
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip)
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return proxies
+}
+
+// trustedProxies returns the parsed TrustedProxies, parsing them once
+func (r *Router) trustedProxies() []*net.IPNet {
+	r.trustedOnce.Do(func() {
+		r.trusted = parseTrustedProxies(r.TrustedProxies)
+	})
+	return r.trusted
+}
+
+// isTrustedProxy reports whether ip is in one of the trusted proxy ranges
+func isTrustedProxy(ip net.IP, proxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
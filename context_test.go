@@ -0,0 +1,976 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testContextKey struct{}
+
+func TestSetRequest(t *testing.T) {
+	r := New()
+
+	var seenByErrorHandler interface{}
+	r.ErrorHandler = func(c *Context, err error) {
+		seenByErrorHandler = c.Request.Context().Value(testContextKey{})
+		c.NoContent(http.StatusInternalServerError)
+	}
+
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			ctx := context.WithValue(c.Request.Context(), testContextKey{}, "tenant-a")
+			req := c.Request.WithContext(ctx)
+			req.Header.Set("X-Tenant", "tenant-a")
+			c.SetRequest(req)
+			c.SetRequest(nil) // no-op
+			return next(c)
+		}
+	})
+
+	var value interface{}
+	var header string
+	r.Get("/", func(c *Context) error {
+		value = c.Request.Context().Value(testContextKey{})
+		header = c.Header("X-Tenant")
+		return errors.New("fail")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if value != "tenant-a" {
+		t.Errorf("expected handler to see replaced request context, got %v", value)
+	}
+	if header != "tenant-a" {
+		t.Errorf("expected helpers to read the replaced request, got %q", header)
+	}
+	if seenByErrorHandler != "tenant-a" {
+		t.Errorf("expected ErrorHandler to see replaced request, got %v", seenByErrorHandler)
+	}
+}
+
+func TestWildcardPath(t *testing.T) {
+	tests := []struct {
+		capture string
+		want    string
+	}{
+		{"docs/readme.md", "docs/readme.md"},
+		{"foo/", "foo"},
+		{"a//b/./c", "a/b/c"},
+		{"../../etc/passwd", "etc/passwd"},
+		{"a/../../b", "b"},
+		{`..\..\windows`, "windows"},
+		{"", ""},
+		{"..", ""},
+	}
+
+	for _, tt := range tests {
+		c := &Context{Params: Params{"filepath": tt.capture}}
+		if got := c.WildcardPath("filepath"); got != tt.want {
+			t.Errorf("WildcardPath(%q): expected %q, got %q", tt.capture, tt.want, got)
+		}
+	}
+}
+
+func TestStoreSnapshotRedactsSensitiveValues(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	c.Set("user_id", 42)
+	c.SetSensitive("token", "s3cret")
+	c.SetSensitive("password", "hunter2")
+	c.Set("password", "changed") // stays sensitive
+
+	snapshot := c.StoreSnapshot()
+
+	if snapshot["user_id"] != 42 {
+		t.Errorf("expected user_id to be 42, got %v", snapshot["user_id"])
+	}
+	for _, key := range []string{"token", "password"} {
+		if snapshot[key] != "[REDACTED]" {
+			t.Errorf("expected %s to be redacted, got %v", key, snapshot[key])
+		}
+	}
+
+	// Sensitive values are still readable directly
+	if token, _ := c.GetString("token"); token != "s3cret" {
+		t.Errorf("expected token to be readable via Get, got %q", token)
+	}
+
+	// The snapshot is a copy
+	snapshot["user_id"] = 0
+	if v, _ := c.GetInt("user_id"); v != 42 {
+		t.Error("modifying the snapshot changed the store")
+	}
+}
+
+func TestParamInt(t *testing.T) {
+	r := New()
+
+	var id int
+	var id64 int64
+	var intErr, int64Err, missingErr error
+	r.Get("/users/:id", func(c *Context) error {
+		id, intErr = c.ParamInt("id")
+		id64, int64Err = c.ParamInt64("id")
+		_, missingErr = c.ParamInt("user_id")
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	serve := func(path string) {
+		req := httptest.NewRequest("GET", path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("/users/123")
+	if intErr != nil || id != 123 {
+		t.Errorf("ParamInt: expected 123, got %d (%v)", id, intErr)
+	}
+	if int64Err != nil || id64 != 123 {
+		t.Errorf("ParamInt64: expected 123, got %d (%v)", id64, int64Err)
+	}
+
+	if !errors.Is(missingErr, ErrParamNotFound) {
+		t.Errorf("expected ErrParamNotFound for an undeclared parameter, got %v", missingErr)
+	}
+
+	serve("/users/abc")
+	var se *StatusError
+	if !errors.As(intErr, &se) || se.Code != http.StatusBadRequest {
+		t.Errorf("expected a 400 StatusError for a non-numeric value, got %v", intErr)
+	}
+	if errors.Is(intErr, ErrParamNotFound) {
+		t.Error("a non-numeric value should not be reported as missing")
+	}
+	if !errors.As(int64Err, &se) || se.Code != http.StatusBadRequest {
+		t.Errorf("expected a 400 StatusError from ParamInt64, got %v", int64Err)
+	}
+}
+
+func TestMustParam(t *testing.T) {
+	r := New()
+
+	var id, page string
+	var recovered interface{}
+	r.Get("/users/:user_id/posts/:page?", func(c *Context) error {
+		id = c.MustParam("user_id")
+		page = c.MustParam("page")
+		defer func() { recovered = recover() }()
+		c.MustParam("userid")
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7/posts", nil))
+
+	if id != "7" || page != "" {
+		t.Errorf("expected user_id 7 and an empty page, got %q and %q", id, page)
+	}
+	if msg, _ := recovered.(string); !strings.Contains(msg, `"userid"`) {
+		t.Errorf("expected a panic naming the undeclared parameter, got %v", recovered)
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	r := New()
+	accepted := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetStatus(http.StatusAccepted)
+			if c.IsHeaderWritten() {
+				t.Error("expected SetStatus not to write the headers")
+			}
+			return next(c)
+		}
+	}
+	r.Get("/jobs", func(c *Context) error {
+		_, err := c.Writer.Write([]byte("queued"))
+		return err
+	}, WithMiddleware(accepted))
+	r.Get("/jobs/:id", func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	}, WithMiddleware(accepted))
+	r.Post("/jobs", func(c *Context) error {
+		return nil
+	}, WithMiddleware(accepted))
+	r.Get("/late", func(c *Context) error {
+		c.Writer.Write([]byte("done"))
+		c.SetStatus(http.StatusTeapot)
+		return nil
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"GET", "/jobs", http.StatusAccepted},
+		{"GET", "/jobs/1", http.StatusOK},
+		{"POST", "/jobs", http.StatusAccepted},
+		{"GET", "/late", http.StatusOK},
+	}
+	for _, buffered := range []bool{false, true} {
+		r.BufferResponses = buffered
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("%s %s (buffered %v): expected status %d, got %d", tt.method, tt.path, buffered, tt.status, w.Code)
+			}
+		}
+	}
+}
+
+func TestBytesWritten(t *testing.T) {
+	r := New()
+	var before, after int
+	r.Get("/", func(c *Context) error {
+		before = c.BytesWritten()
+		c.Writer.Write([]byte("hello, "))
+		fmt.Fprintf(c.Writer, "%s", "world")
+		after = c.BytesWritten()
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if before != 0 || after != len("hello, world") {
+		t.Errorf("expected 0 then %d bytes, got %d then %d", len("hello, world"), before, after)
+	}
+}
+
+func TestCreatedAndSeeOtherRoute(t *testing.T) {
+	r := New()
+
+	r.Get("/users/:id", func(c *Context) error { return nil }, WithName("user_show"))
+	r.Get("/files/*filepath", func(c *Context) error { return nil }, WithName("file_show"))
+	r.Post("/users", func(c *Context) error {
+		return c.Created("user_show", "42")
+	})
+	r.Post("/files", func(c *Context) error {
+		return c.SeeOtherRoute("file_show", "docs/a b.txt")
+	})
+	r.Post("/broken", func(c *Context) error {
+		return c.Created("user_show")
+	})
+
+	req := httptest.NewRequest("POST", "/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/users/42" {
+		t.Errorf("expected Location /users/42, got %q", location)
+	}
+
+	req = httptest.NewRequest("POST", "/files", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusSeeOther {
+		t.Errorf("expected status 303, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/files/docs/a%20b.txt" {
+		t.Errorf("expected Location /files/docs/a%%20b.txt, got %q", location)
+	}
+
+	req = httptest.NewRequest("POST", "/broken", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 for missing params, got %d", w.Code)
+	}
+
+	// A Context built outside the router has no routes to point at
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/users", nil))
+	if err := c.Created("user_show", "42"); err == nil {
+		t.Error("expected Created to fail without a router")
+	}
+	if err := c.SeeOtherRoute("user_show", "42"); err == nil {
+		t.Error("expected SeeOtherRoute to fail without a router")
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	type searchRequest struct {
+		Q       string   `query:"q"`
+		Page    int      `query:"page"`
+		Exact   bool     `query:"exact"`
+		Tags    []string `query:"tags"`
+		Missing string   `query:"missing"`
+		Other   string
+	}
+
+	req := httptest.NewRequest("GET", "/search?q=go&page=2&exact=true&tags=a&tags=b&Other=x", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	got := searchRequest{Missing: "default"}
+	if err := c.BindQuery(&got); err != nil {
+		t.Fatalf("BindQuery failed: %v", err)
+	}
+
+	if got.Q != "go" || got.Page != 2 || !got.Exact {
+		t.Errorf("unexpected scalar fields %+v", got)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "a" || got.Tags[1] != "b" {
+		t.Errorf("expected tags [a b], got %v", got.Tags)
+	}
+	if got.Missing != "default" {
+		t.Errorf("expected absent parameters to leave fields unchanged, got %q", got.Missing)
+	}
+	if got.Other != "" {
+		t.Errorf("expected untagged fields to be skipped, got %q", got.Other)
+	}
+
+	req = httptest.NewRequest("GET", "/search?page=two", nil)
+	c = newContext(httptest.NewRecorder(), req)
+
+	err := c.BindQuery(&got)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusBadRequest {
+		t.Errorf("expected a 400 StatusError for an invalid int, got %v", err)
+	}
+}
+
+func TestFormValue(t *testing.T) {
+	r := New()
+
+	var name, missing string
+	r.Post("/signup", func(c *Context) error {
+		name = c.FormValue("name")
+		missing = c.FormValue("missing")
+
+		// A second call reads the cached form, not the drained body
+		if c.FormValue("name") != name {
+			t.Error("expected repeated FormValue calls to return the same value")
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/signup", strings.NewReader("name=Ada+Lovelace&role=admin"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if name != "Ada Lovelace" {
+		t.Errorf("expected name %q, got %q", "Ada Lovelace", name)
+	}
+	if missing != "" {
+		t.Errorf("expected empty value for a missing field, got %q", missing)
+	}
+}
+
+func TestFormFile(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "notes")
+	fw, _ := mw.CreateFormFile("upload", "notes.txt")
+	fw.Write([]byte("file contents"))
+	mw.Close()
+
+	r := New()
+	r.MaxMultipartMemory = 1024
+
+	var title, contents, filename string
+	var missingErr error
+	r.Post("/upload", func(c *Context) error {
+		title = c.FormValue("title")
+
+		fh, err := c.FormFile("upload")
+		if err != nil {
+			return err
+		}
+		filename = fh.Filename
+
+		f, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, _ := io.ReadAll(f)
+		contents = string(data)
+
+		_, missingErr = c.FormFile("other")
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if title != "notes" || filename != "notes.txt" || contents != "file contents" {
+		t.Errorf("unexpected form values: title=%q filename=%q contents=%q", title, filename, contents)
+	}
+	if !errors.Is(missingErr, http.ErrMissingFile) {
+		t.Errorf("expected http.ErrMissingFile for a missing file, got %v", missingErr)
+	}
+}
+
+func TestBindJSONStrict(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	body := `{"name":"x","bogus":1}`
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c := newContext(httptest.NewRecorder(), req)
+
+	var got user
+	err := c.BindJSONStrict(&got)
+	if err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("expected an error naming the unknown field, got %v", err)
+	}
+
+	// BindJSON keeps ignoring unknown fields
+	req = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c = newContext(httptest.NewRecorder(), req)
+
+	if err := c.BindJSON(&got); err != nil || got.Name != "x" {
+		t.Errorf("expected BindJSON to ignore unknown fields, got %+v (%v)", got, err)
+	}
+}
+
+func TestContextWithValue(t *testing.T) {
+	r := New()
+
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.WithValue(testContextKey{}, "acme")
+			return next(c)
+		}
+	})
+
+	var fromContext, fromRequest interface{}
+	r.Get("/", func(c *Context) error {
+		fromContext = c.Context().Value(testContextKey{})
+		fromRequest = c.Request.Context().Value(testContextKey{})
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if fromContext != "acme" {
+		t.Errorf("expected c.Context() to carry the value, got %v", fromContext)
+	}
+	if fromRequest != "acme" {
+		t.Errorf("expected the request's context to carry the value, got %v", fromRequest)
+	}
+	if req.Context().Value(testContextKey{}) != nil {
+		t.Error("expected the original request to be left unchanged")
+	}
+}
+
+func TestStream(t *testing.T) {
+	r := New()
+
+	next := make(chan struct{})
+	r.Get("/events", func(c *Context) error {
+		n := 0
+		return c.Stream(http.StatusOK, "text/event-stream", func(w io.Writer) bool {
+			<-next
+			n++
+			fmt.Fprintf(w, "data: %d\n", n)
+			return n < 3
+		})
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+
+	// Each chunk must arrive before the next one is produced
+	reader := bufio.NewReader(resp.Body)
+	for i := 1; i <= 3; i++ {
+		next <- struct{}{}
+
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading chunk %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("data: %d\n", i); line != want {
+			t.Errorf("expected chunk %q, got %q", want, line)
+		}
+	}
+
+	if rest, _ := io.ReadAll(reader); len(rest) != 0 {
+		t.Errorf("expected the stream to end after step returns false, got %q", rest)
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error {
+		items := make(chan interface{})
+		go func() {
+			defer close(items)
+			for i := 1; i <= 3; i++ {
+				items <- map[string]int{"id": i}
+			}
+		}()
+		return c.JSONStream(http.StatusOK, items)
+	})
+	r.Get("/empty", func(c *Context) error {
+		items := make(chan interface{})
+		close(items)
+		return c.JSONStream(http.StatusOK, items)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+	var users []map[string]int
+	if err := json.Unmarshal(w.Body.Bytes(), &users); err != nil {
+		t.Fatalf("invalid JSON %q: %v", w.Body.String(), err)
+	}
+	if len(users) != 3 || users[0]["id"] != 1 || users[2]["id"] != 3 {
+		t.Errorf("unexpected items: %v", users)
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected application/json, got %q", w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Error("expected the items to be flushed while waiting")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/empty", nil))
+	if w.Body.String() != "[]" {
+		t.Errorf("expected [], got %q", w.Body.String())
+	}
+}
+
+func TestJSONStreamCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	c := newContext(httptest.NewRecorder(), req)
+
+	items := make(chan interface{})
+	done := make(chan error, 1)
+	go func() { done <- c.JSONStream(http.StatusOK, items) }()
+
+	items <- "first"
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// hijackRecorder is a ResponseRecorder that supports hijacking
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	r := New()
+
+	var hijackErr error
+	r.Get("/ws", func(c *Context) error {
+		conn, _, err := c.Writer.Hijack()
+		hijackErr = err
+		if err == nil {
+			conn.Close()
+		}
+		return nil
+	})
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+
+	if hijackErr != nil {
+		t.Fatalf("expected hijack to succeed, got %v", hijackErr)
+	}
+	if !rec.hijacked {
+		t.Error("expected Hijack to reach the underlying writer")
+	}
+
+	// ResponseRecorder doesn't implement http.Hijacker
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ws", nil))
+
+	if hijackErr == nil {
+		t.Error("expected an error when the writer can't be hijacked")
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newContext(rec, httptest.NewRequest("GET", "/", nil))
+
+	var _ http.Flusher = c.Writer
+	c.Writer.Flush()
+
+	if !rec.Flushed {
+		t.Error("expected Flush to reach the underlying writer")
+	}
+	if !c.IsHeaderWritten() {
+		t.Error("expected Flush to write the header")
+	}
+}
+
+func TestFileAndAttachment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(file, []byte("id,name\n1,alice\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+	r.Get("/file", func(c *Context) error {
+		return c.File(file)
+	})
+	r.Get("/download", func(c *Context) error {
+		return c.Attachment(file, "users report.csv")
+	})
+	r.Get("/missing", func(c *Context) error {
+		return c.Attachment(filepath.Join(dir, "missing.csv"), "missing.csv")
+	})
+
+	req := httptest.NewRequest("GET", "/file", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n1,alice\n" {
+		t.Errorf("expected the file contents, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("expected text/csv content type, got %q", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "" {
+		t.Errorf("expected no Content-Disposition, got %q", got)
+	}
+
+	// Range requests are supported
+	req = httptest.NewRequest("GET", "/file", nil)
+	req.Header.Set("Range", "bytes=0-1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent || w.Body.String() != "id" {
+		t.Errorf("expected 206 with the range, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/download", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="users report.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/missing", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "" {
+		t.Errorf("expected no Content-Disposition on the error, got %q", got)
+	}
+}
+
+func TestFileMissingReturnsError(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	err := c.File(filepath.Join(t.TempDir(), "missing.txt"))
+
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusNotFound {
+		t.Fatalf("expected a 404 StatusError, got %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the error to wrap os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestJSONPretty(t *testing.T) {
+	r := New()
+	r.Get("/debug", func(c *Context) error {
+		return c.JSONPretty(http.StatusOK, map[string]interface{}{
+			"name": "alice",
+			"tags": []string{"admin"},
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/debug", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	expected := "{\n  \"name\": \"alice\",\n  \"tags\": [\n    \"admin\"\n  ]\n}\n"
+	if w.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	data := map[string]string{"link": "<a href=\"/x\">x</a>"}
+
+	r := New()
+	r.Get("/json", func(c *Context) error {
+		return c.JSON(http.StatusOK, data)
+	})
+	r.Get("/pretty", func(c *Context) error {
+		return c.JSONPretty(http.StatusOK, data)
+	})
+
+	// HTML is escaped by default
+	req := httptest.NewRequest("GET", "/json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if expected := `{"link":"\u003ca href=\"/x\"\u003ex\u003c/a\u003e"}` + "\n"; w.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.Body.String())
+	}
+
+	r.JSONEncoder = func(w io.Writer) *json.Encoder {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc
+	}
+
+	req = httptest.NewRequest("GET", "/json", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if expected := `{"link":"<a href=\"/x\">x</a>"}` + "\n"; w.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/pretty", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if expected := "{\n  \"link\": \"<a href=\\\"/x\\\">x</a>\"\n}\n"; w.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.Body.String())
+	}
+}
+
+type xmlOrder struct {
+	XMLName xml.Name `xml:"order"`
+	ID      int      `xml:"id,attr"`
+	Item    string   `xml:"item"`
+	Qty     int      `xml:"qty"`
+}
+
+func TestXML(t *testing.T) {
+	r := New()
+	r.Get("/orders/:id", func(c *Context) error {
+		return c.XML(http.StatusOK, xmlOrder{ID: 7, Item: "widget", Qty: 3})
+	})
+
+	req := httptest.NewRequest("GET", "/orders/7", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("expected application/xml, got %q", ct)
+	}
+	expected := xml.Header + `<order id="7"><item>widget</item><qty>3</qty></order>`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.Body.String())
+	}
+
+	// The response decodes back into the same struct
+	var got xmlOrder
+	if err := xml.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.ID != 7 || got.Item != "widget" || got.Qty != 3 {
+		t.Errorf("unexpected round trip %+v", got)
+	}
+}
+
+func TestBindXML(t *testing.T) {
+	var got xmlOrder
+	r := New()
+	r.Post("/orders", func(c *Context) error {
+		if err := c.BindXML(&got); err != nil {
+			return NewStatusError(http.StatusBadRequest, err.Error())
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	body := `<?xml version="1.0"?><order id="9"><item>gadget</item><qty>2</qty></order>`
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+	if got.ID != 9 || got.Item != "gadget" || got.Qty != 2 {
+		t.Errorf("unexpected binding %+v", got)
+	}
+
+	req = httptest.NewRequest("POST", "/orders", strings.NewReader("<order>"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for malformed XML, got %d", w.Code)
+	}
+}
+
+func TestETag(t *testing.T) {
+	r := New()
+	calls := 0
+	r.Get("/users/:id", func(c *Context) error {
+		if c.ETag("v42") {
+			return nil
+		}
+		calls++
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	etag := w.Header().Get("ETag")
+	if etag != `"v42"` {
+		t.Fatalf(`expected ETag "v42", got %s`, etag)
+	}
+
+	// The second request sends the tag back and gets 304 without a body
+	req = httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected no body, got %q", w.Body.String())
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("expected the 304 to carry the ETag, got %q", w.Header().Get("ETag"))
+	}
+	if calls != 1 {
+		t.Errorf("expected the body to be built once, got %d", calls)
+	}
+}
+
+func TestETagMatching(t *testing.T) {
+	tests := []struct {
+		tag         string
+		ifNoneMatch string
+		method      string
+		expected    bool
+	}{
+		{`"v1"`, `"v1"`, "GET", true},
+		{"v1", `"v0", "v1"`, "GET", true},
+		{`W/"v1"`, `"v1"`, "GET", true},
+		{`"v1"`, `W/"v1"`, "HEAD", true},
+		{`"v1"`, "*", "GET", true},
+		{`"v1"`, `"v2"`, "GET", false},
+		{`"v1"`, "", "GET", false},
+		{`"v1"`, `"v1"`, "PUT", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/", nil)
+		if tt.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		c := newContext(w, req)
+
+		if got := c.ETag(tt.tag); got != tt.expected {
+			t.Errorf("ETag(%s) with If-None-Match %s on %s: expected %v, got %v", tt.tag, tt.ifNoneMatch, tt.method, tt.expected, got)
+		}
+		if tt.expected && w.Code != http.StatusNotModified {
+			t.Errorf("ETag(%s): expected status 304, got %d", tt.tag, w.Code)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		expected   string
+	}{
+		{"remote addr", nil, "192.0.2.1:1234", nil, "", "192.0.2.1:1234"},
+		{"single ip", nil, "10.0.0.1:1234", []string{"203.0.113.7"}, "", "203.0.113.7"},
+		{"multi-hop", nil, "10.0.0.1:1234", []string{" 203.0.113.7 , 10.0.0.2, 10.0.0.3"}, "", "203.0.113.7"},
+		{"empty entries", nil, "10.0.0.1:1234", []string{", 203.0.113.7"}, "", "203.0.113.7"},
+		{"real ip", nil, "10.0.0.1:1234", nil, "203.0.113.7", "203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.1:1234", []string{"203.0.113.7, 10.0.0.2"}, "", "203.0.113.7"},
+		{"spoofed entry", []string{"10.0.0.0/8"}, "10.0.0.1:1234", []string{"1.2.3.4, 203.0.113.7, 10.0.0.2"}, "", "203.0.113.7"},
+		{"several headers", []string{"10.0.0.1", "10.0.0.2"}, "10.0.0.1:1234", []string{"1.2.3.4", "203.0.113.7, 10.0.0.2"}, "", "203.0.113.7"},
+		{"untrusted remote", []string{"10.0.0.0/8"}, "198.51.100.9:1234", []string{"1.2.3.4"}, "1.2.3.4", "198.51.100.9:1234"},
+		{"trusted real ip", []string{"10.0.0.1"}, "10.0.0.1:1234", nil, "203.0.113.7", "203.0.113.7"},
+		{"all hops trusted", []string{"10.0.0.0/8"}, "10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		{"ipv6 proxy", []string{"::1"}, "[::1]:1234", []string{"2001:db8::7"}, "", "2001:db8::7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			r := New()
+			r.TrustedProxies = tt.trusted
+			c := newContext(httptest.NewRecorder(), req)
+			c.router = r
+
+			if got := c.ClientIP(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
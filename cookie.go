@@ -0,0 +1,71 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// SetSignedCookie sets a cookie whose value is signed with HMAC-SHA256 and
+// key, so that SignedCookie can tell if the client changed it. The value
+// is still readable by the client; signing only prevents tampering.
+//
+//	c.SetSignedCookie(&http.Cookie{Name: "user_id", Value: "42", HttpOnly: true}, key)
+//
+// The signature covers the cookie's name as well as its value, so a signed
+// value can't be moved to another cookie. The cookie passed in is not
+// modified. Use a random key of at least 32 bytes.
+func (c *Context) SetSignedCookie(cookie *http.Cookie, key []byte) {
+	signed := *cookie
+	signed.Value = signCookieValue(cookie.Name, cookie.Value, key)
+	c.SetCookie(&signed)
+}
+
+// SignedCookie returns the value of a cookie set with SetSignedCookie,
+// after checking its signature against key. It returns http.ErrNoCookie if
+// the request has no such cookie, and ErrInvalidSignature if the cookie
+// wasn't signed with key or has been changed.
+func (c *Context) SignedCookie(name string, key []byte) (string, error) {
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := verifyCookieValue(name, cookie.Value, key)
+	if !ok {
+		return "", ErrInvalidSignature
+	}
+	return value, nil
+}
+
+// signCookieValue appends the signature of a cookie's name and value
+func signCookieValue(name, value string, key []byte) string {
+	return value + "." + cookieSignature(name, value, key)
+}
+
+// verifyCookieValue checks a value signed by signCookieValue and returns
+// it without the signature
+func verifyCookieValue(name, signed string, key []byte) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+
+	value, signature := signed[:i], signed[i+1:]
+	expected := cookieSignature(name, value, key)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", false
+	}
+	return value, true
+}
+
+// cookieSignature signs a cookie's name and value with key
+func cookieSignature(name, value string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignedCookie(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	r := New()
+	r.Get("/set", func(c *Context) error {
+		c.SetSignedCookie(&http.Cookie{Name: "user_id", Value: "42.5", Path: "/"}, key)
+		return nil
+	})
+	r.Get("/get", func(c *Context) error {
+		value, err := c.SignedCookie("user_id", key)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, value)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/set", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !strings.HasPrefix(cookies[0].Value, "42.5.") {
+		t.Fatalf("expected a signed user_id cookie, got %v", cookies)
+	}
+	signed := cookies[0].Value
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		status int
+		body   string
+	}{
+		{"valid", &http.Cookie{Name: "user_id", Value: signed}, http.StatusOK, "42.5"},
+		{"tampered value", &http.Cookie{Name: "user_id", Value: "43" + strings.TrimPrefix(signed, "42")}, http.StatusInternalServerError, ""},
+		{"unsigned", &http.Cookie{Name: "user_id", Value: "42"}, http.StatusInternalServerError, ""},
+		{"missing", nil, http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/get", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSignedCookieErrors(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	w := httptest.NewRecorder()
+	newContext(w, httptest.NewRequest("GET", "/", nil)).SetSignedCookie(&http.Cookie{Name: "session", Value: "abc"}, key)
+	signed := w.Result().Cookies()[0].Value
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		key    []byte
+		err    error
+	}{
+		{"missing", nil, key, http.ErrNoCookie},
+		{"wrong key", &http.Cookie{Name: "session", Value: signed}, []byte("another key"), ErrInvalidSignature},
+		{"moved to another cookie", &http.Cookie{Name: "admin", Value: signed}, key, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			name := "session"
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+				name = tt.cookie.Name
+			}
+
+			_, err := newContext(httptest.NewRecorder(), req).SignedCookie(name, tt.key)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
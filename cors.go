@@ -0,0 +1,131 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed to make cross-origin requests,
+	// such as "https://example.com". "*" allows any origin, but can't be
+	// combined with AllowCredentials.
+	AllowOrigins []string
+
+	// AllowMethods lists the methods allowed in preflight responses.
+	// The default is GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string
+
+	// AllowHeaders lists the request headers allowed in preflight responses.
+	// When empty, the headers the preflight request asks for are allowed.
+	AllowHeaders []string
+
+	// ExposeHeaders lists the response headers the browser lets scripts read
+	ExposeHeaders []string
+
+	// AllowCredentials lets the browser send cookies and HTTP authentication.
+	// It requires AllowOrigins to list the origins, as letting any site make
+	// credentialed requests would expose users' data to all of them.
+	AllowCredentials bool
+
+	// MaxAge is how long the browser may cache a preflight response.
+	// Zero leaves the header out.
+	MaxAge time.Duration
+}
+
+// defaultCORSMethods are the methods allowed when AllowMethods is empty
+var defaultCORSMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+
+// CORS returns middleware that handles Cross-Origin Resource Sharing.
+// Requests from an allowed origin get an Access-Control-Allow-Origin header;
+// preflight requests (OPTIONS with Access-Control-Request-Method) are
+// answered with 204 No Content and the allowed methods and headers, without
+// calling the handler. Requests from other origins are passed on unchanged,
+// so the browser blocks them.
+//
+// Example:
+//
+//	r.HandleOPTIONS = true
+//	r.Use(router.CORS(router.CORSConfig{
+//		AllowOrigins:     []string{"https://app.example.com"},
+//		AllowHeaders:     []string{"Content-Type", "Authorization"},
+//		AllowCredentials: true,
+//		MaxAge:           time.Hour,
+//	}))
+//
+// Register CORS with Use so it also sees preflight requests for paths
+// without an OPTIONS route; these reach the middleware when HandleOPTIONS
+// is set.
+//
+// Panics if AllowOrigins contains "*" and AllowCredentials is set.
+func CORS(cfg CORSConfig) MiddlewareFunc {
+	allowAll := false
+	origins := make(map[string]bool, len(cfg.AllowOrigins))
+	for _, origin := range cfg.AllowOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	if allowAll && cfg.AllowCredentials {
+		panic(`CORS: AllowOrigins "*" can't be used with AllowCredentials; list the allowed origins instead`)
+	}
+
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(cfg.MaxAge / time.Second))
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			// The response depends on the origin, so caches must key on it
+			c.Writer.Header().Add("Vary", "Origin")
+
+			origin := c.Header("Origin")
+			if origin == "" || !allowAll && !origins[strings.ToLower(origin)] {
+				return next(c)
+			}
+
+			if allowAll {
+				c.SetHeader("Access-Control-Allow-Origin", "*")
+			} else {
+				c.SetHeader("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				c.SetHeader("Access-Control-Allow-Credentials", "true")
+			}
+
+			// Answer preflight requests without running the handler
+			if c.Request.Method == http.MethodOptions && c.Header("Access-Control-Request-Method") != "" {
+				c.SetHeader("Access-Control-Allow-Methods", allowMethods)
+
+				headers := allowHeaders
+				if headers == "" {
+					headers = c.Header("Access-Control-Request-Headers")
+				}
+				if headers != "" {
+					c.SetHeader("Access-Control-Allow-Headers", headers)
+				}
+				if maxAge != "" {
+					c.SetHeader("Access-Control-Max-Age", maxAge)
+				}
+				return c.NoContent(http.StatusNoContent)
+			}
+
+			if exposeHeaders != "" {
+				c.SetHeader("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			return next(c)
+		}
+	}
+}
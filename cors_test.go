@@ -0,0 +1,146 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCORSSimpleRequest(t *testing.T) {
+	r := New()
+	r.Use(CORS(CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"X-Total-Count"},
+	}))
+	r.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "users" {
+		t.Errorf("expected 200 users, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("expected origin to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Total-Count" {
+		t.Errorf("expected exposed headers, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("expected Vary: Origin, got %q", got)
+	}
+
+	// Other origins get no CORS headers
+	req = httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no Access-Control-Allow-Origin, got %q", got)
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	r := New()
+	r.Use(CORS(CORSConfig{AllowOrigins: []string{"*"}}))
+	r.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected *, got %q", got)
+	}
+}
+
+func TestCORSWildcardOriginWithCredentialsPanics(t *testing.T) {
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "AllowCredentials") {
+			t.Errorf("expected CORS to panic, got %q", msg)
+		}
+	}()
+	CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := New()
+	r.HandleOPTIONS = true
+	r.Use(CORS(CORSConfig{
+		AllowOrigins: []string{"https://app.example.com"},
+		AllowMethods: []string{"GET", "POST"},
+		AllowHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:       time.Hour,
+	}))
+
+	called := false
+	r.Post("/users", func(c *Context) error {
+		called = true
+		return c.NoContent(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", w.Code)
+	}
+	if called {
+		t.Error("preflight should not call the handler")
+	}
+
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+		"Access-Control-Max-Age":       "3600",
+	}
+	for header, want := range expected {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("expected %s %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestCORSPreflightEchoesRequestHeaders(t *testing.T) {
+	r := New()
+	r.HandleOPTIONS = true
+	r.Use(CORS(CORSConfig{AllowOrigins: []string{"https://app.example.com"}}))
+	r.Put("/users/:id", func(c *Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	req.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "X-Custom" {
+		t.Errorf("expected requested headers to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, HEAD, POST, PUT, PATCH, DELETE" {
+		t.Errorf("expected default methods, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrParamNotFound is returned by the typed param helpers, such as
+// Context.ParamInt, when the route has no parameter with the given name
+var ErrParamNotFound = errors.New("route parameter not found")
+
+// ErrInvalidSignature is returned by Context.SignedCookie when a cookie's
+// signature is missing or doesn't match its value
+var ErrInvalidSignature = errors.New("invalid cookie signature")
+
+// StatusError is an error that carries an HTTP status code.
+// Handlers can return a StatusError to control the status of the error
+// response, and the default ErrorHandler renders it as:
+//
+//	{"error": "<message>"}
+//
+// The router's own NotFound and MethodNotAllowed handlers return StatusErrors too,
+// so routing errors and handler errors share the same response format.
+//
+// Example:
+//
+//	r.Get("/users/:id", func(c *Context) error {
+//	    user, ok := users[c.Param("id")]
+//	    if !ok {
+//	        return router.NotFound("user not found")
+//	    }
+//	    return c.JSON(200, user)
+//	})
+type StatusError struct {
+	Code    int
+	Message string
+
+	// Err is the underlying cause, if any. It is available to errors.Is
+	// and errors.As but is not included in the error message, so it isn't
+	// sent to the client by the default ErrorHandler.
+	Err error
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// NewStatusError creates a StatusError with the given status code and message.
+// If message is empty, the standard status text is used.
+func NewStatusError(code int, message string) *StatusError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &StatusError{Code: code, Message: message}
+}
+
+// NotFound creates a 404 StatusError
+func NotFound(message string) *StatusError {
+	return NewStatusError(http.StatusNotFound, message)
+}
+
+// MethodNotAllowed creates a 405 StatusError
+func MethodNotAllowed(message string) *StatusError {
+	return NewStatusError(http.StatusMethodNotAllowed, message)
+}
+
+// statusCode returns the HTTP status code for an error.
+// StatusErrors report their own code, a body over Router.MaxBodyBytes is a
+// 413, and anything else is a 500.
+func statusCode(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusInternalServerError
+}
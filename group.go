@@ -24,100 +24,242 @@ import "strings"
 //	
 //	// Add more middleware to a group
 //	api.Use(loggingMiddleware)
+//
+// Group middleware is looked up when a request is served rather than when a
+// route is registered, so middleware added with Use also applies to routes and
+// resources registered on the group (or its nested groups) before the call.
+// Use must not be called concurrently with requests being served.
 type Group struct {
 	router     *Router
+	parent     *Group
 	prefix     string
 	middleware []MiddlewareFunc
+	version    string // API version recorded on routes in the group
+	disabled   bool   // routes registered on a disabled group are dropped
+	namePrefix string // prepended to resource route names, as set by Nest
+
+	// NotFound handles requests under the group's prefix that match no
+	// route, in place of the router's NotFound. When groups are nested,
+	// the innermost group with a NotFound handler is used. Like the
+	// router's, it runs inside the global middleware only.
+	//
+	//	api := r.Group("/api")
+	//	api.NotFound = func(c *Context) error {
+	//	    return c.JSON(404, map[string]string{"error": "no such endpoint"})
+	//	}
+	NotFound HandlerFunc
 }
 
 // Group creates a new route group with the given prefix
 func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *Group {
-	return &Group{
+	return r.addGroup(&Group{
 		router:     r,
 		prefix:     prefix,
 		middleware: middleware,
+	})
+}
+
+// addGroup records a group under its prefix, so its NotFound handler can
+// be found on a miss. NotFound is set after the group is created, so every
+// group that could have one is recorded; groups without a prefix, which
+// contain every path, can't.
+func (r *Router) addGroup(g *Group) *Group {
+	prefix := strings.TrimSuffix(g.prefix, "/")
+	if prefix == "" {
+		return g
+	}
+
+	r.routesMu.Lock()
+	defer r.routesMu.Unlock()
+
+	if r.groups == nil {
+		r.groups = make(map[string][]*Group)
 	}
+	r.groups[prefix] = append(r.groups[prefix], g)
+	return g
 }
 
-// Use adds middleware to the group
+// notFoundHandler returns the NotFound handler for a path: that of the
+// group with the longest prefix containing the path, or the router's.
+// Only the groups at the path's own prefixes are looked at.
+func (r *Router) notFoundHandler(path string) HandlerFunc {
+	prefix := strings.TrimSuffix(path, "/")
+	for {
+		for _, g := range r.groups[prefix] {
+			if g.NotFound != nil {
+				return g.NotFound
+			}
+		}
+
+		i := strings.LastIndexByte(prefix, '/')
+		if i <= 0 {
+			return r.NotFound
+		}
+		prefix = prefix[:i]
+	}
+}
+
+// When returns a group that only registers routes when cond is true.
+// It's useful for gating debug or staging-only endpoints behind a check
+// made at startup, without wrapping every registration in an if:
+//
+//	debug := os.Getenv("APP_ENV") != "production"
+//	r.When(debug).Get("/debug/routes", listRoutes)
+//	r.When(debug).Resources("/fixtures", &FixtureController{})
+func (r *Router) When(cond bool) *Group {
+	return r.addGroup(&Group{
+		router:   r,
+		disabled: !cond,
+	})
+}
+
+// When returns a copy of the group that only registers routes when cond is true.
+// See Router.When() for usage examples.
+func (g *Group) When(cond bool) *Group {
+	return g.router.addGroup(&Group{
+		router:   g.router,
+		parent:   g,
+		prefix:   g.prefix,
+		version:  g.version,
+		disabled: g.disabled || !cond,
+	})
+}
+
+// Use adds middleware to the group.
+// The middleware applies to every route in the group, including routes
+// registered before Use was called.
 func (g *Group) Use(middleware ...MiddlewareFunc) {
 	g.middleware = append(g.middleware, middleware...)
+	g.router.chainVersion.Add(1)
+}
+
+// chain returns the group's middleware, preceded by that of its parent groups
+func (g *Group) chain() []MiddlewareFunc {
+	if g.parent == nil {
+		return g.middleware
+	}
+	parent := g.parent.chain()
+	all := make([]MiddlewareFunc, 0, len(parent)+len(g.middleware))
+	all = append(all, parent...)
+	return append(all, g.middleware...)
 }
 
 // handle registers a route with the group's prefix and middleware.
 // This is an internal method. Use HTTP method helpers (Get, Post, etc.) instead.
-func (g *Group) handle(method, path string, handler HandlerFunc, name string, middleware ...MiddlewareFunc) {
-	fullPath := g.prefix + path
-
-	// Combine group middleware with route-specific middleware
-	allMiddleware := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
-	allMiddleware = append(allMiddleware, g.middleware...)
-	allMiddleware = append(allMiddleware, middleware...)
+func (g *Group) handle(method, path string, handler HandlerFunc, cfg *routeConfig) {
+	if g.disabled {
+		return
+	}
 
-	g.router.handle(method, fullPath, handler, name, allMiddleware...)
+	g.router.register(g, method, g.prefix+path, handler, cfg)
 }
 
 // Get registers a GET route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Get(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("GET", path, handler, name, middleware...)
+	g.handle("GET", path, handler, parseRouteOptions(opts))
+}
+
+// GetAll registers a GET route on the group under several paths.
+// See Router.GetAll() for how the paths are named.
+func (g *Group) GetAll(paths []string, handler HandlerFunc, opts ...RouteOption) {
+	if g.disabled {
+		return
+	}
+
+	fullPaths := make([]string, len(paths))
+	for i, path := range paths {
+		fullPaths[i] = g.prefix + path
+	}
+
+	g.router.registerAll(g, "GET", fullPaths, handler, parseRouteOptions(opts))
 }
 
 // Post registers a POST route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Post(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("POST", path, handler, name, middleware...)
+	g.handle("POST", path, handler, parseRouteOptions(opts))
 }
 
 // Put registers a PUT route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Put(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("PUT", path, handler, name, middleware...)
+	g.handle("PUT", path, handler, parseRouteOptions(opts))
 }
 
 // Patch registers a PATCH route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Patch(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("PATCH", path, handler, name, middleware...)
+	g.handle("PATCH", path, handler, parseRouteOptions(opts))
 }
 
 // Delete registers a DELETE route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Delete(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("DELETE", path, handler, name, middleware...)
+	g.handle("DELETE", path, handler, parseRouteOptions(opts))
 }
 
 // Head registers a HEAD route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Head(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("HEAD", path, handler, name, middleware...)
+	g.handle("HEAD", path, handler, parseRouteOptions(opts))
 }
 
 // Options registers an OPTIONS route on the group with optional configuration.
 // See Router.Get() for usage examples.
 func (g *Group) Options(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	g.handle("OPTIONS", path, handler, name, middleware...)
+	g.handle("OPTIONS", path, handler, parseRouteOptions(opts))
 }
 
-// Group creates a nested group with combined prefix and middleware
-func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) *Group {
-	// Combine parent and child middleware
-	allMiddleware := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
-	allMiddleware = append(allMiddleware, g.middleware...)
-	allMiddleware = append(allMiddleware, middleware...)
+// Any registers the handler on the group for every standard HTTP method.
+// See Router.Any().
+func (g *Group) Any(path string, handler HandlerFunc, opts ...RouteOption) {
+	if g.disabled {
+		return
+	}
+	g.router.registerMethods(g, allMethods, g.prefix+path, handler, parseRouteOptions(opts))
+}
 
-	return &Group{
+// Match registers the handler on the group for each of the given methods.
+// See Router.Match().
+func (g *Group) Match(methods []string, path string, handler HandlerFunc, opts ...RouteOption) {
+	if g.disabled {
+		return
+	}
+	g.router.registerMethods(g, methods, g.prefix+path, handler, parseRouteOptions(opts))
+}
+
+// Group creates a nested group with combined prefix and middleware.
+// The parent group's middleware runs before the nested group's own middleware.
+func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) *Group {
+	return g.router.addGroup(&Group{
 		router:     g.router,
+		parent:     g,
 		prefix:     g.prefix + prefix,
-		middleware: allMiddleware,
-	}
+		middleware: middleware,
+		version:    g.version,
+		disabled:   g.disabled,
+		namePrefix: g.namePrefix,
+	})
+}
+
+// Version creates a nested group for an API version.
+// It works like Group("/"+version) but also records the version on every
+// route registered in it, for introspection through NamedRoutes.
+//
+// Explicit route names (WithName) and resource route names are prefixed
+// with the version so they don't clash between versions. Automatically
+// generated names already include the version from the path.
+//
+//	api := r.Group("/api")
+//	v1 := api.Version("v1")
+//	v1.Get("/users/:id", showUser, WithName("user_show")) // GET /api/v1/users/:id, named "v1_user_show"
+//	v1.Resources("/posts", &PostController{})             // named "v1_posts_index", "v1_posts_show", ...
+//	v1.Get("/status", status)                             // named "api_v1_status_index"
+func (g *Group) Version(version string, middleware ...MiddlewareFunc) *Group {
+	v := g.Group("/"+version, middleware...)
+	v.version = version
+	return v
 }
 
 // Resources registers RESTful routes for a controller within the group
@@ -126,36 +268,26 @@ func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) *Group {
 //	api := r.Group("/api/v1")
 //	api.Resources("/users", &UserController{})
 //	api.Resources("/posts", &PostController{}, Only(IndexAction, ShowAction))
-func (g *Group) Resources(path string, controller Controller, opts ...ResourceOption) {
+func (g *Group) Resources(path string, controller Controller, opts ...ResourceOption) *ResourceScope {
 	config := parseResourceOptions(opts)
-
-	// Combine group middleware with resource middleware
-	allMiddleware := make([]MiddlewareFunc, 0, len(g.middleware)+len(config.middleware))
-	allMiddleware = append(allMiddleware, g.middleware...)
-	allMiddleware = append(allMiddleware, config.middleware...)
-	config.middleware = allMiddleware
-
-	// Add the group prefix to the path
-	fullPath := g.prefix + path
-
-	// Extract resource name from path (e.g., "/users" -> "users")
-	resourceName := path
-	if idx := strings.LastIndex(path, "/"); idx >= 0 {
-		resourceName = path[idx+1:]
+	if !g.disabled {
+		// Add the group prefix to the path; missing actions are skipped
+		g.router.registerResources(g, path, g.prefix+path, controller, config, false)
 	}
+	return newResourceScope(g.router, g, path, config)
+}
 
-	routes := getResourceRoutes(fullPath)
-
-	for _, route := range routes {
-		if !config.shouldIncludeAction(route.action) {
-			continue
-		}
-
-		handler := getControllerHandler(controller, route.action)
-		if handler != nil {
-			// Generate route name like "users_index", "users_show", etc.
-			routeName := resourceName + "_" + string(route.action)
-			g.router.handle(route.method, route.path, handler, routeName, config.middleware...)
-		}
+// Resource registers RESTful routes for a singular resource within the group
+// Example:
+//
+//	account := r.Group("/account")
+//	account.Resource("/profile", &ProfileController{})
+func (g *Group) Resource(path string, controller Controller, opts ...ResourceOption) *ResourceScope {
+	config := parseResourceOptions(opts)
+	config.singular = true
+	if !g.disabled {
+		// Add the group prefix to the path; missing actions are skipped
+		g.router.registerResources(g, path, g.prefix+path, controller, config, false)
 	}
+	return newResourceScope(g.router, g, path, config)
 }
@@ -0,0 +1,228 @@
+package router
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// GzipOption is a functional option for configuring Gzip
+type GzipOption func(*gzipConfig)
+
+// gzipConfig holds the configuration for Gzip
+type gzipConfig struct {
+	level     int
+	minLength int
+}
+
+// WithGzipLevel sets the compression level, from gzip.BestSpeed to
+// gzip.BestCompression. The default is gzip.DefaultCompression.
+func WithGzipLevel(level int) GzipOption {
+	return func(cfg *gzipConfig) {
+		cfg.level = level
+	}
+}
+
+// WithGzipMinLength sets the smallest response body, in bytes, that is
+// compressed. Smaller bodies are sent as they are, since compressing them
+// saves little. The default is 1024.
+func WithGzipMinLength(n int) GzipOption {
+	return func(cfg *gzipConfig) {
+		cfg.minLength = n
+	}
+}
+
+// Gzip returns middleware that compresses responses with gzip for clients
+// that accept it. Responses that already have a Content-Encoding, and bodies
+// shorter than the minimum length, are sent unchanged.
+//
+// Example:
+//
+//	r.Use(router.Gzip())
+//	r.Use(router.Gzip(router.WithGzipLevel(gzip.BestSpeed), router.WithGzipMinLength(512)))
+//
+// The response is buffered until the minimum length is reached, so
+// c.IsHeaderWritten and c.GetStatus behave as without compression.
+func Gzip(opts ...GzipOption) MiddlewareFunc {
+	cfg := &gzipConfig{
+		level:     gzip.DefaultCompression,
+		minLength: 1024,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if _, err := gzip.NewWriterLevel(nil, cfg.level); err != nil {
+		panic(fmt.Sprintf("invalid gzip level %d", cfg.level))
+	}
+
+	pool := &sync.Pool{
+		New: func() interface{} {
+			zw, _ := gzip.NewWriterLevel(nil, cfg.level)
+			return zw
+		},
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+			if !acceptsGzip(c.Request.Header.Get("Accept-Encoding")) {
+				return next(c)
+			}
+
+			gw := &gzipWriter{ResponseWriter: c.Writer.ResponseWriter, cfg: cfg, pool: pool}
+			c.Writer.ResponseWriter = gw
+			defer func() {
+				gw.close()
+				c.Writer.ResponseWriter = gw.ResponseWriter
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		q := strings.ReplaceAll(params, " ", "")
+		if q == "q=0" || strings.HasPrefix(q, "q=0.") && strings.Trim(q[4:], "0") == "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter compresses the response written through it. It holds back
+// the status and body until it has seen enough of the body to decide
+// whether to compress.
+type gzipWriter struct {
+	http.ResponseWriter
+	cfg  *gzipConfig
+	pool *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	zw      *gzip.Writer
+}
+
+// WriteHeader records the status until the body decides the encoding
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers the body until the minimum length is reached, then
+// compresses it, or sends it unchanged if it shouldn't be compressed
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.cfg.minLength {
+			return len(b), nil
+		}
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// start decides whether to compress, writes the header and any buffered
+// body
+func (w *gzipWriter) start() error {
+	w.decided = true
+
+	h := w.ResponseWriter.Header()
+	if len(w.buf) >= w.cfg.minLength && h.Get("Content-Encoding") == "" && bodyAllowed(w.status) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+
+		w.zw = w.pool.Get().(*gzip.Writer)
+		w.zw.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.zw != nil {
+		_, err := w.zw.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends whatever is still held back and finishes the gzip stream
+func (w *gzipWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			// Nothing was written; leave the response to the ErrorHandler
+			return
+		}
+		w.start()
+	}
+
+	if w.zw != nil {
+		w.zw.Close()
+		w.pool.Put(w.zw)
+		w.zw = nil
+	}
+}
+
+// Flush sends what has been written so far, compressed if the body has
+// reached the minimum length
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.start()
+	}
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes through to the underlying writer
+func (w *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// bodyAllowed reports whether a response with the status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
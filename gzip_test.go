@@ -0,0 +1,122 @@
+package router
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	r := New()
+	r.Use(Gzip())
+
+	body := strings.Repeat("hello gzip ", 500)
+
+	var headerWritten bool
+	var status int
+	r.Get("/large", func(c *Context) error {
+		err := c.String(http.StatusCreated, body)
+		headerWritten = c.IsHeaderWritten()
+		status = c.GetStatus()
+		return err
+	})
+
+	req := httptest.NewRequest("GET", "/large", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected Content-Encoding gzip, got %q", w.Header().Get("Content-Encoding"))
+	}
+	if !headerWritten || status != http.StatusCreated {
+		t.Errorf("expected IsHeaderWritten and GetStatus to report 201, got %v %d", headerWritten, status)
+	}
+
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if string(decoded) != body {
+		t.Error("decoded body doesn't match the original")
+	}
+}
+
+func TestGzipSkips(t *testing.T) {
+	r := New()
+	r.Use(Gzip(WithGzipMinLength(100)))
+
+	large := strings.Repeat("x", 1000)
+	r.Get("/small", func(c *Context) error {
+		return c.String(http.StatusOK, "tiny")
+	})
+	r.Get("/encoded", func(c *Context) error {
+		c.SetHeader("Content-Encoding", "br")
+		return c.String(http.StatusOK, large)
+	})
+	r.Get("/large", func(c *Context) error {
+		return c.String(http.StatusOK, large)
+	})
+
+	tests := []struct {
+		path           string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"/small", "gzip", "", "tiny"},
+		{"/encoded", "gzip", "br", large},
+		{"/large", "", "", large},
+		{"/large", "gzip;q=0, deflate", "", large},
+		{"/large", "*", "gzip", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.acceptEncoding, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.encoding, got)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected the body to be sent unchanged")
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestGzipErrorResponse(t *testing.T) {
+	r := New()
+	r.Use(Gzip())
+	r.Get("/fail", func(c *Context) error {
+		return NotFound("missing")
+	})
+
+	req := httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "missing") {
+		t.Errorf("expected the ErrorHandler's response, got %q", w.Body.String())
+	}
+}
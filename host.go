@@ -0,0 +1,143 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// hostSwitch dispatches requests for one method and path to the route
+// registered for the request's host. It is stored in the tree in place of
+// the individual routes.
+type hostSwitch struct {
+	// Host-scoped routes, exact hosts before wildcard hosts
+	routes []hostRoute
+
+	// Route registered without WithHost, used when no host matches
+	fallback *hostRoute
+}
+
+// hostRoute is a single route registered on a hostSwitch
+type hostRoute struct {
+	host       string
+	handler    HandlerFunc
+	middleware []interface{}
+}
+
+// routeKey identifies the routes sharing a method and path
+func routeKey(method, path string) string {
+	return method + " /" + strings.Trim(path, "/")
+}
+
+// addHostRoute registers a route on the hostSwitch for method and path,
+// creating the switch on first use. host is empty for the unscoped route.
+func (r *Router) addHostRoute(method, path, host string, handler HandlerFunc, middleware []interface{}) error {
+	if strings.Contains(host, "*") && !strings.HasPrefix(host, "*.") {
+		return fmt.Errorf("invalid host %q for %s %s: wildcard must be a leading \"*.\"", host, method, path)
+	}
+
+	key := routeKey(method, path)
+	sw := r.hosts[key]
+	if sw == nil {
+		sw = &hostSwitch{}
+
+		// Keep a route already registered at this path as the fallback
+		if n := r.tree.Route(method, path); n != nil {
+			if h, ok := n.Handlers[method].(HandlerFunc); ok {
+				sw.fallback = &hostRoute{handler: h, middleware: n.Middleware}
+			}
+		}
+
+		// Takes the place of the existing route, if any
+		if err := r.tree.ReplaceRoute(method, path, HandlerFunc(sw.serve), nil); err != nil {
+			return err
+		}
+		r.hosts[key] = sw
+	}
+
+	route := hostRoute{
+		host:       strings.ToLower(host),
+		handler:    handler,
+		middleware: middleware,
+	}
+
+	if route.host == "" {
+		if sw.fallback != nil && !r.AllowOverride {
+			return fmt.Errorf("duplicate route %s %s: already registered", method, path)
+		}
+		sw.fallback = &route
+		return nil
+	}
+
+	for i := range sw.routes {
+		if sw.routes[i].host == route.host {
+			if !r.AllowOverride {
+				return fmt.Errorf("duplicate route %s %s for host %s: already registered", method, path, host)
+			}
+			sw.routes[i] = route
+			return nil
+		}
+	}
+	sw.add(route)
+	return nil
+}
+
+// add inserts a route, keeping exact hosts ahead of wildcard hosts
+func (sw *hostSwitch) add(route hostRoute) {
+
+	if strings.HasPrefix(route.host, "*.") {
+		sw.routes = append(sw.routes, route)
+		return
+	}
+
+	// Exact hosts go ahead of the first wildcard host
+	i := 0
+	for i < len(sw.routes) && !strings.HasPrefix(sw.routes[i].host, "*.") {
+		i++
+	}
+	sw.routes = append(sw.routes, hostRoute{})
+	copy(sw.routes[i+1:], sw.routes[i:])
+	sw.routes[i] = route
+}
+
+// match returns the route for host, or nil if there is none
+func (sw *hostSwitch) match(host string) *hostRoute {
+	for i := range sw.routes {
+		if matchHost(sw.routes[i].host, host) {
+			return &sw.routes[i]
+		}
+	}
+	return sw.fallback
+}
+
+// serve runs the route matching the request's host through its middleware
+func (sw *hostSwitch) serve(c *Context) error {
+	route := sw.match(requestHost(c.Request.Host))
+	if route == nil {
+		return c.router.notFoundHandler(c.Request.URL.Path)(c)
+	}
+
+	h := route.handler
+	middleware := resolveMiddleware(route.middleware)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h(c)
+}
+
+// requestHost returns the lowercased host without its port
+func requestHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// matchHost reports whether host matches pattern. A pattern beginning with
+// "*." matches any subdomain of the rest of the pattern.
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return pattern == host
+}
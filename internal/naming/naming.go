@@ -1,12 +1,17 @@
 package naming
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Route stores information about a named route
 type Route struct {
 	Name    string
 	Pattern string
 	Method  string
+	Version string // API version of the group the route was registered on, if any
+	AliasOf string // name of the route this name is an alias of, if any
 }
 
 // Registry manages named routes for reverse routing and code generation
@@ -21,8 +26,36 @@ func NewRegistry() *Registry {
 	}
 }
 
-// Add registers a named route
-func (r *Registry) Add(name, pattern, method string) {
+// Check returns the error Add would return for the name, without
+// registering it
+func (r *Registry) Check(name, pattern, method string) error {
+	if existing, ok := r.routes[name]; ok && (existing.Pattern != pattern || existing.Method != method) {
+		return fmt.Errorf("route name %q is already used by %s %s", name, existing.Method, existing.Pattern)
+	}
+	return nil
+}
+
+// Add registers a named route. It returns an error if the name is already
+// used by a route with a different pattern or method; registering the
+// same route again is allowed.
+func (r *Registry) Add(name, pattern, method string) error {
+	if err := r.Check(name, pattern, method); err != nil {
+		return err
+	}
+	if _, ok := r.routes[name]; ok {
+		return nil
+	}
+
+	r.routes[name] = &Route{
+		Name:    name,
+		Pattern: pattern,
+		Method:  method,
+	}
+	return nil
+}
+
+// Set registers a named route, replacing any route already using the name
+func (r *Registry) Set(name, pattern, method string) {
 	r.routes[name] = &Route{
 		Name:    name,
 		Pattern: pattern,
@@ -66,9 +99,9 @@ func GenerateName(path, method string) string {
 	hasParams := false
 
 	for _, segment := range segments {
-		if strings.HasPrefix(segment, ":") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") || strings.HasPrefix(segment, "{") {
 			hasParams = true
-			// Skip parameter segments in the base name
+			// Skip parameter and wildcard segments in the base name
 			continue
 		}
 		// Replace hyphens with underscores for valid identifiers
@@ -2,7 +2,10 @@ package tree
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // NodeType represents the type of node in the radix tree
@@ -36,53 +39,436 @@ type Node struct {
 
 	// Middleware chain for this specific route (stored as []interface{})
 	Middleware []interface{}
+
+	// TrailingSlash records whether the route was registered with a
+	// trailing slash, as in /users/. Lookups match either form.
+	TrailingSlash bool
+
+	// Validator reports whether a segment satisfies the param's constraint,
+	// as in :id(int) or :name([a-z]+\.png). Nil for params without a
+	// constraint.
+	Validator func(string) bool
+
+	// static indexes the static children by Path, for search
+	static *edge
+}
+
+// edge is an edge of a node's static index: a radix tree over the Paths of
+// its static children, in which Paths sharing a prefix share the edges for
+// it. Finding a segment compares each of its bytes once, however many
+// static siblings it has.
+type edge struct {
+	label string  // the part of a Path matched along this edge
+	node  *Node   // the static child whose Path ends with this edge, if any
+	edges []*edge // edges continuing the Path, sorted by their distinct first bytes
+}
+
+// child returns the index of the edge from e starting with b, or where
+// one would be inserted, and whether it exists
+func (e *edge) child(b byte) (int, bool) {
+	// A binary search, written out as sort.Search is slower on hot paths
+	i, j := 0, len(e.edges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if e.edges[h].label[0] < b {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i, i < len(e.edges) && e.edges[i].label[0] == b
+}
+
+// insert adds a static child reached by path from e
+func (e *edge) insert(path string, node *Node) {
+	for path != "" {
+		i, ok := e.child(path[0])
+		if !ok {
+			e.edges = append(e.edges, nil)
+			copy(e.edges[i+1:], e.edges[i:])
+			e.edges[i] = &edge{label: path, node: node}
+			return
+		}
+		next := e.edges[i]
+
+		// Split the edge where path leaves its label
+		common := 0
+		for common < len(next.label) && common < len(path) && next.label[common] == path[common] {
+			common++
+		}
+		if common < len(next.label) {
+			rest := &edge{label: next.label[common:], node: next.node, edges: next.edges}
+			next.label = next.label[:common]
+			next.node = nil
+			next.edges = []*edge{rest}
+		}
+
+		e = next
+		path = path[common:]
+	}
+	e.node = node
+}
+
+// find returns the static child reached by path from e, or nil
+func (e *edge) find(path string) *Node {
+	for path != "" {
+		i, ok := e.child(path[0])
+		if !ok || !strings.HasPrefix(path, e.edges[i].label) {
+			return nil
+		}
+		e = e.edges[i]
+		path = path[len(e.label):]
+	}
+	return e.node
+}
+
+// priority orders a node among its siblings for search: static segments
+// first, then constrained params, plain params, and wildcards last
+func (n *Node) priority() int {
+	switch {
+	case n.NType == Static:
+		return 0
+	case n.NType == Param && n.Validator != nil:
+		return 1
+	case n.NType == Param:
+		return 2
+	}
+	return 3
+}
+
+// child returns the child matching a route segment, or nil
+func (n *Node) child(segment string, nType NodeType) *Node {
+	for _, child := range n.Children {
+		if child.Path == segment && child.NType == nType {
+			return child
+		}
+	}
+	return nil
+}
+
+// addChild inserts child after the siblings with the same or a higher
+// precedence, so search tries children in precedence order. Static
+// children are kept sorted by Path.
+func (n *Node) addChild(child *Node) {
+	i := len(n.Children)
+	for i > 0 && (n.Children[i-1].priority() > child.priority() ||
+		child.NType == Static && n.Children[i-1].NType == Static && n.Children[i-1].Path > child.Path) {
+		i--
+	}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[i+1:], n.Children[i:])
+	n.Children[i] = child
+
+	if child.NType == Static {
+		if n.static == nil {
+			n.static = &edge{}
+		}
+		n.static.insert(child.Path, child)
+	}
+}
+
+// constraints maps the names usable in a :param(name) constraint to the
+// validators checking a segment against them
+var constraints = map[string]func(string) bool{
+	"int":   isInt,
+	"uuid":  isUUID,
+	"alpha": isAlpha,
+}
+
+// parseConstraint returns the validator for a param constraint. A name
+// such as int refers to a built-in constraint; anything else is a regular
+// expression that must match the whole segment.
+func parseConstraint(constraint string) (func(string) bool, error) {
+	if validator, ok := constraints[constraint]; ok {
+		return validator, nil
+	}
+	if isIdentifier(constraint) {
+		return nil, fmt.Errorf("unknown constraint %q", constraint)
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", constraint, err)
+	}
+	return re.MatchString, nil
+}
+
+// isIdentifier reports whether s looks like a constraint name rather than
+// a regular expression
+func isIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// parseParam splits a param segment such as :id(int) into its name and
+// constraint, without the leading ':' or '*'
+func parseParam(segment string) (name, constraint string) {
+	name = segment[1:]
+	if i := strings.IndexByte(name, '('); i >= 0 && strings.HasSuffix(name, ")") {
+		return name[:i], name[i+1 : len(name)-1]
+	}
+	return name, ""
 }
 
-// Tree manages route trees for each HTTP method
+// isInt reports whether s is a base 10 integer with an optional sign
+func isInt(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isAlpha reports whether s is made up only of ASCII letters
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// methodIndex maps the standard HTTP methods to a slot in Tree.roots.
+// Methods not listed here return -1 and live in Tree.custom instead.
+func methodIndex(method string) int {
+	switch method {
+	case "GET":
+		return 0
+	case "POST":
+		return 1
+	case "PUT":
+		return 2
+	case "PATCH":
+		return 3
+	case "DELETE":
+		return 4
+	case "HEAD":
+		return 5
+	case "OPTIONS":
+		return 6
+	case "CONNECT":
+		return 7
+	case "TRACE":
+		return 8
+	}
+	return -1
+}
+
+// numMethods is the number of standard methods handled by methodIndex
+const numMethods = 9
+
+// Tree manages route trees for each HTTP method.
+//
+// Adding routes is safe from several goroutines, and lookups made while
+// routes are added take a read lock. Once Freeze is called the tree is
+// read-only: adding routes fails and lookups take no lock.
 type Tree struct {
-	roots map[string]*Node
+	// mu guards the nodes until the tree is frozen
+	mu     sync.RWMutex
+	frozen atomic.Bool
+
+	// Roots for the standard methods, indexed by methodIndex
+	roots [numMethods]*Node
+
+	// Roots for any non-standard methods
+	custom map[string]*Node
+
+	// Methods with a root, in registration order
+	methods []string
 }
 
 // New creates a new Tree instance
 func New() *Tree {
 	return &Tree{
-		roots: make(map[string]*Node),
+		custom: make(map[string]*Node),
 	}
 }
 
-// AddRoute adds a route to the radix tree
+// root returns the root node for a method, or nil if none is registered
+func (t *Tree) root(method string) *Node {
+	if i := methodIndex(method); i >= 0 {
+		return t.roots[i]
+	}
+	return t.custom[method]
+}
+
+// setRoot stores the root node for a method
+func (t *Tree) setRoot(method string, n *Node) {
+	if i := methodIndex(method); i >= 0 {
+		t.roots[i] = n
+	} else {
+		t.custom[method] = n
+	}
+	t.methods = append(t.methods, method)
+}
+
+// Freeze makes the tree read-only. Routes added afterwards are rejected
+// with an error, and lookups no longer lock.
+func (t *Tree) Freeze() {
+	t.mu.Lock()
+	t.frozen.Store(true)
+	t.mu.Unlock()
+}
+
+// Frozen reports whether Freeze has been called
+func (t *Tree) Frozen() bool {
+	return t.frozen.Load()
+}
+
+// rlock read-locks the tree unless it is frozen, reporting whether it
+// locked so the caller knows to unlock
+func (t *Tree) rlock() bool {
+	if t.frozen.Load() {
+		return false
+	}
+	t.mu.RLock()
+	return true
+}
+
+// AddRoute adds a route to the radix tree. It returns an error if the
+// method already has a handler for the path.
 func (t *Tree) AddRoute(method, path string, handler interface{}, middleware []interface{}) error {
+	return t.addRoute(method, path, handler, middleware, false)
+}
+
+// ReplaceRoute adds a route to the radix tree like AddRoute, replacing the
+// handler and middleware of a route already registered for the method and
+// path instead of returning an error
+func (t *Tree) ReplaceRoute(method, path string, handler interface{}, middleware []interface{}) error {
+	return t.addRoute(method, path, handler, middleware, true)
+}
+
+// NormalizePath rewrites the {param} and {*wildcard} segments of a route
+// path, as written in OpenAPI, to the equivalent :param and *wildcard
+// segments used internally. Both styles can be mixed in one path.
+func NormalizePath(path string) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+			if name := segment[1 : len(segment)-1]; name[0] == '*' {
+				segments[i] = name
+			} else {
+				segments[i] = ":" + name
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// addRoute adds a route, replacing an existing one only if replace is set
+func (t *Tree) addRoute(method, path string, handler interface{}, middleware []interface{}, replace bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = NormalizePath(path)
+
+	if t.frozen.Load() {
+		return fmt.Errorf("cannot add route %s %s: the tree is frozen", method, path)
+	}
 	if len(path) == 0 || path[0] != '/' {
 		return fmt.Errorf("invalid route path %q for %s: path must begin with '/'", path, method)
 	}
 
-	// Ensure root node exists for this method
-	if t.roots[method] == nil {
-		t.roots[method] = &Node{
+	// Ensure root node exists for this method; a new one is added to the
+	// tree once the route is known to be valid
+	root := t.root(method)
+	newRoot := root == nil
+	if newRoot {
+		root = &Node{
 			Path:     "/",
 			Handlers: make(map[string]interface{}),
 			Children: make([]*Node, 0),
 		}
 	}
 
-	root := t.roots[method]
-
 	if path == "/" {
+		if _, exists := root.Handlers[method]; exists && !replace {
+			return fmt.Errorf("duplicate route %s %s: a handler is already registered", method, path)
+		}
+		if newRoot {
+			t.setRoot(method, root)
+		}
 		root.Handlers[method] = handler
 		root.Pattern = path
 		root.Middleware = middleware
 		return nil
 	}
 
+	trailingSlash := strings.HasSuffix(path, "/")
+
 	// Remove leading and trailing slashes, split path
 	path = strings.Trim(path, "/")
 	segments := strings.Split(path, "/")
+	pattern := "/" + path
+
+	// An optional last param, as in /posts/:id/:slug?, also ends the route
+	// at the node before it
+	optional := false
+	for i, segment := range segments {
+		if !strings.HasSuffix(segment, "?") {
+			continue
+		}
+		if len(segment) < 3 || segment[0] != ':' {
+			return fmt.Errorf("invalid optional segment %q in route %s /%s: only params can be optional", segment, method, path)
+		}
+		if i != len(segments)-1 {
+			return fmt.Errorf("optional parameter %q must be the last segment in route %s /%s", segment, method, path)
+		}
+		segments[i] = strings.TrimSuffix(segment, "?")
+		optional = true
+	}
 
-	// Validate no duplicate parameter names
+	// Validate no duplicate parameter names, and that a wildcard, which
+	// consumes the rest of the path, is the last segment
 	paramNames := make(map[string]int)
 	for i, segment := range segments {
+		if len(segment) > 0 && segment[0] == '*' && i != len(segments)-1 {
+			return fmt.Errorf("wildcard segment %q must be the last segment in route %s /%s", segment, method, path)
+		}
 		if len(segment) > 0 && (segment[0] == ':' || segment[0] == '*') {
-			paramName := segment[1:]
+			paramName, _ := parseParam(segment)
 			if firstIndex, exists := paramNames[paramName]; exists {
 				return fmt.Errorf("duplicate parameter %q in route %s /%s: first occurrence at segment %d, duplicate at segment %d", paramName, method, path, firstIndex, i)
 			}
@@ -90,48 +476,88 @@ func (t *Tree) AddRoute(method, path string, handler interface{}, middleware []i
 		}
 	}
 
-	current := root
+	// Parse every segment before changing the tree, so that a route that
+	// fails to register leaves nothing behind
+	types := make([]NodeType, len(segments))
+	names := make([]string, len(segments))
+	validators := make([]func(string) bool, len(segments))
 	for i, segment := range segments {
-		// Determine node type
-		nType := Static
-		paramName := ""
-
-		if len(segment) > 0 {
-			if segment[0] == ':' {
-				nType = Param
-				paramName = segment[1:]
-			} else if segment[0] == '*' {
-				nType = Wildcard
-				paramName = segment[1:]
+		if len(segment) == 0 {
+			continue
+		}
+		if segment[0] == ':' {
+			types[i] = Param
+			var constraint string
+			names[i], constraint = parseParam(segment)
+			if constraint != "" {
+				var err error
+				if validators[i], err = parseConstraint(constraint); err != nil {
+					return fmt.Errorf("%w for parameter %q in route %s /%s", err, names[i], method, path)
+				}
 			}
+		} else if segment[0] == '*' {
+			types[i] = Wildcard
+			names[i] = segment[1:]
 		}
+	}
+
+	// ends reports whether the route ends at the node for segments[:i+1],
+	// the last segment or, for an optional one, the one before it
+	ends := func(i int) bool {
+		return i == len(segments)-1 || optional && i == len(segments)-2
+	}
 
-		// Look for existing child with matching segment
-		var next *Node
-		for _, child := range current.Children {
-			if child.Path == segment && child.NType == nType {
-				next = child
-				break
+	// Check the nodes the route ends at for an existing handler
+	if !replace {
+		existing := []*Node{}
+		if optional && len(segments) == 1 {
+			existing = append(existing, root)
+		}
+		for i, current := 0, root; current != nil && i < len(segments); i++ {
+			current = current.child(segments[i], types[i])
+			if current != nil && ends(i) {
+				existing = append(existing, current)
+			}
+		}
+		for _, n := range existing {
+			if _, exists := n.Handlers[method]; exists {
+				return fmt.Errorf("duplicate route %s %s: already registered as %s %s", method, pattern, method, n.Pattern)
 			}
 		}
+	}
+
+	// setHandler ends the route at n
+	setHandler := func(n *Node) {
+		n.Handlers[method] = handler
+		n.Pattern = pattern
+		n.Middleware = middleware
+		n.TrailingSlash = trailingSlash
+	}
 
-		// Create new node if no match found
+	if newRoot {
+		t.setRoot(method, root)
+	}
+	if optional && len(segments) == 1 {
+		setHandler(root)
+	}
+
+	current := root
+	for i, segment := range segments {
+		next := current.child(segment, types[i])
 		if next == nil {
 			next = &Node{
 				Path:      segment,
-				NType:     nType,
-				ParamName: paramName,
+				NType:     types[i],
+				ParamName: names[i],
 				Handlers:  make(map[string]interface{}),
 				Children:  make([]*Node, 0),
+				Validator: validators[i],
 			}
-			current.Children = append(current.Children, next)
+			current.addChild(next)
 		}
 
-		// If this is the last segment, set the handler
-		if i == len(segments)-1 {
-			next.Handlers[method] = handler
-			next.Pattern = "/" + strings.Join(segments, "/")
-			next.Middleware = middleware
+		if ends(i) {
+			setHandler(next)
 		}
 
 		current = next
@@ -142,69 +568,161 @@ func (t *Tree) AddRoute(method, path string, handler interface{}, middleware []i
 
 // Find finds a matching route in the tree and returns handler, params, and middleware
 func (t *Tree) Find(method, path string) (interface{}, map[string]string, []interface{}) {
-	root := t.roots[method]
-	if root == nil {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+	return t.find(method, path)
+}
+
+// find is Find without locking
+func (t *Tree) find(method, path string) (interface{}, map[string]string, []interface{}) {
+	n, params := t.lookup(method, path)
+	if n == nil {
 		return nil, nil, nil
 	}
+	return n.Handlers[method], params, n.Middleware
+}
+
+// Lookup finds the node that handles a route and returns it with the params
+// extracted from the path, or nil params if the route has none. It returns
+// a nil node if no route matches.
+func (t *Tree) Lookup(method, path string) (*Node, map[string]string) {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+	return t.lookup(method, path)
+}
+
+// lookup is Lookup without locking
+func (t *Tree) lookup(method, path string) (*Node, map[string]string) {
+	root := t.root(method)
+	if root == nil {
+		return nil, nil
+	}
 
 	if path == "/" {
-		if handler, ok := root.Handlers[method]; ok {
-			return handler, nil, root.Middleware
+		if _, ok := root.Handlers[method]; ok {
+			return root, nil
 		}
-		return nil, nil, nil
+		return nil, nil
 	}
 
 	path = strings.Trim(path, "/")
-	segments := strings.Split(path, "/")
-	params := make(map[string]string)
 
-	handler, middleware := search(root, segments, 0, params, method)
-	return handler, params, middleware
+	// Allocated by search only when a param is bound
+	var params map[string]string
+
+	n := search(root, path, 0, &params, method)
+	if n == nil {
+		return nil, nil
+	}
+	return n, params
 }
 
-// search recursively searches for a matching route
-func search(n *Node, segments []string, index int, params map[string]string, method string) (interface{}, []interface{}) {
+// search recursively searches for the node handling a route, binding
+// params into *params, which it creates on the first one. The segments
+// still to match are those of path from offset start on; path has no
+// leading or trailing slash, and start is past its end once all are matched.
+func search(n *Node, path string, start int, params *map[string]string, method string) *Node {
 	// If we've matched all segments, check if this node has a handler
-	if index == len(segments) {
-		if handler, ok := n.Handlers[method]; ok {
-			return handler, n.Middleware
+	if start > len(path) {
+		if _, ok := n.Handlers[method]; ok {
+			return n
 		}
-		return nil, nil
+		return nil
 	}
 
-	segment := segments[index]
+	end := strings.IndexByte(path[start:], '/')
+	if end < 0 {
+		end = len(path)
+	} else {
+		end += start
+	}
+	segment := path[start:end]
 
-	// Try children in order: static > param > wildcard
+	// Try children in order: static > constrained param > param > wildcard
+	if n.static != nil {
+		if child := n.static.find(segment); child != nil {
+			if found := search(child, path, end+1, params, method); found != nil {
+				return found
+			}
+		}
+	}
 	for _, child := range n.Children {
 		switch child.NType {
-		case Static:
-			if child.Path == segment {
-				if handler, middleware := search(child, segments, index+1, params, method); handler != nil {
-					return handler, middleware
-				}
-			}
 		case Param:
-			params[child.ParamName] = segment
-			if handler, middleware := search(child, segments, index+1, params, method); handler != nil {
-				return handler, middleware
+			if child.Validator != nil && !child.Validator(segment) {
+				continue
+			}
+			bind(params, child.ParamName, segment)
+			if found := search(child, path, end+1, params, method); found != nil {
+				return found
 			}
-			delete(params, child.ParamName) // backtrack
+			delete(*params, child.ParamName) // backtrack
 		case Wildcard:
 			// Wildcard matches everything remaining
-			params[child.ParamName] = strings.Join(segments[index:], "/")
-			if handler, ok := child.Handlers[method]; ok {
-				return handler, child.Middleware
+			bind(params, child.ParamName, path[start:])
+			if _, ok := child.Handlers[method]; ok {
+				return child
 			}
 		}
 	}
 
-	return nil, nil
+	return nil
+}
+
+// bind sets a param, creating the map on first use
+func bind(params *map[string]string, name, value string) {
+	if *params == nil {
+		*params = make(map[string]string)
+	}
+	(*params)[name] = value
+}
+
+// Route returns the node registered for exactly this method and pattern,
+// without matching params against request paths. It returns nil if the
+// pattern has not been registered for the method.
+func (t *Tree) Route(method, pattern string) *Node {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+
+	n := t.root(method)
+	if n == nil {
+		return nil
+	}
+
+	if pattern = strings.Trim(NormalizePath(pattern), "/"); pattern != "" {
+		for _, segment := range strings.Split(pattern, "/") {
+			segment = strings.TrimSuffix(segment, "?")
+			var next *Node
+			for _, child := range n.Children {
+				if child.Path == segment {
+					next = child
+					break
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			n = next
+		}
+	}
+
+	if _, ok := n.Handlers[method]; !ok {
+		return nil
+	}
+	return n
 }
 
 // HasMethod checks if any HTTP method has a handler for the given path
 func (t *Tree) HasMethod(path string) bool {
-	for method := range t.roots {
-		handler, _, _ := t.Find(method, path)
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+
+	for _, method := range t.methods {
+		handler, _, _ := t.find(method, path)
 		if handler != nil {
 			return true
 		}
@@ -214,12 +732,82 @@ func (t *Tree) HasMethod(path string) bool {
 
 // GetMethods returns all HTTP methods that have handlers for the given path
 func (t *Tree) GetMethods(path string) []string {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+
 	methods := make([]string, 0)
-	for method := range t.roots {
-		handler, _, _ := t.Find(method, path)
+	for _, method := range t.methods {
+		handler, _, _ := t.find(method, path)
 		if handler != nil {
 			methods = append(methods, method)
 		}
 	}
 	return methods
 }
+
+// Walk calls fn for every route in the tree, method by method in the order
+// the methods were first registered, and depth first within a method. A
+// route with an optional last param ends at two nodes but is visited once.
+// fn must not add routes.
+func (t *Tree) Walk(fn func(method string, n *Node)) {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+
+	seen := make(map[string]bool)
+	t.walk(func(method string, n *Node, depth int) {
+		if _, ok := n.Handlers[method]; ok && !seen[method+" "+n.Pattern] {
+			seen[method+" "+n.Pattern] = true
+			fn(method, n)
+		}
+	})
+}
+
+// walk calls fn for every node of each method's tree with its depth below
+// the root, without locking
+func (t *Tree) walk(fn func(method string, n *Node, depth int)) {
+	for _, method := range t.methods {
+		var visit func(n *Node, depth int)
+		visit = func(n *Node, depth int) {
+			fn(method, n, depth)
+			for _, child := range n.Children {
+				visit(child, depth+1)
+			}
+		}
+		visit(t.root(method), 0)
+	}
+}
+
+// TreeStats describes the shape of a Tree, for debugging
+type TreeStats struct {
+	// Nodes is the number of nodes across the trees of all methods
+	Nodes int
+
+	// MaxDepth is the number of segments on the longest path from a root
+	MaxDepth int
+
+	// Routes is the number of routes registered for each method
+	Routes map[string]int
+}
+
+// Stats returns the node count, depth and routes per method of the tree
+func (t *Tree) Stats() TreeStats {
+	if t.rlock() {
+		defer t.mu.RUnlock()
+	}
+
+	stats := TreeStats{Routes: make(map[string]int)}
+	seen := make(map[string]bool)
+	t.walk(func(method string, n *Node, depth int) {
+		stats.Nodes++
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+		if _, ok := n.Handlers[method]; ok && !seen[method+" "+n.Pattern] {
+			seen[method+" "+n.Pattern] = true
+			stats.Routes[method]++
+		}
+	})
+	return stats
+}
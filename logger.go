@@ -0,0 +1,80 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LoggerOption is a functional option for configuring Logger
+type LoggerOption func(*loggerConfig)
+
+// loggerConfig holds the configuration for Logger
+type loggerConfig struct {
+	logger *slog.Logger
+}
+
+// WithLogger sets the slog.Logger requests are logged to.
+// The default is slog.Default().
+func WithLogger(logger *slog.Logger) LoggerOption {
+	return func(cfg *loggerConfig) {
+		cfg.logger = logger
+	}
+}
+
+// Logger returns middleware that logs each request after it has been
+// handled, with its method, path, status, response size in bytes,
+// duration and client IP:
+//
+//	r.Use(router.Logger())
+//	r.Use(router.Logger(router.WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil)))))
+//
+// If the handler returns an error before writing a response, the status
+// logged is the one the default ErrorHandler would send for it (see
+// StatusError), and the error is logged too. Requests with a 5xx status are
+// logged at error level, the rest at info level. Register Logger first so
+// the duration covers the other middleware.
+func Logger(opts ...LoggerOption) MiddlewareFunc {
+	cfg := &loggerConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			start := time.Now()
+			err := next(c)
+			duration := time.Since(start)
+
+			status := c.GetStatus()
+			if err != nil && !c.IsHeaderWritten() {
+				status = statusCode(err)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", c.BytesWritten()),
+				slog.Duration("duration", duration),
+				slog.String("client_ip", c.ClientIP()),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger := cfg.logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := New()
+	r.Use(Logger(WithLogger(logger)))
+	r.Post("/users", func(c *Context) error {
+		return c.String(http.StatusCreated, "created")
+	})
+	r.Get("/users/:id", func(c *Context) error {
+		return NotFound("user not found")
+	})
+	r.Get("/boom", func(c *Context) error {
+		return errors.New("database unavailable")
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		level  string
+		err    string
+		bytes  int
+	}{
+		{"POST", "/users", http.StatusCreated, "INFO", "", len("created")},
+		{"GET", "/users/7", http.StatusNotFound, "INFO", "user not found", 0},
+		{"GET", "/boom", http.StatusInternalServerError, "ERROR", "database unavailable", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			buf.Reset()
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+			}
+
+			if entry["method"] != tt.method || entry["path"] != tt.path {
+				t.Errorf("unexpected method/path in %v", entry)
+			}
+			if entry["status"] != float64(tt.status) {
+				t.Errorf("expected status %d, got %v", tt.status, entry["status"])
+			}
+			if entry["level"] != tt.level {
+				t.Errorf("expected level %s, got %v", tt.level, entry["level"])
+			}
+			if entry["client_ip"] != "192.0.2.1:1234" {
+				t.Errorf("expected client_ip, got %v", entry["client_ip"])
+			}
+			if entry["bytes"] != float64(tt.bytes) {
+				t.Errorf("expected bytes %d, got %v", tt.bytes, entry["bytes"])
+			}
+			if _, ok := entry["duration"]; !ok {
+				t.Error("expected a duration field")
+			}
+			if tt.err != "" && entry["error"] != tt.err {
+				t.Errorf("expected error %q, got %v", tt.err, entry["error"])
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader is the header MethodOverride reads the method from
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverrideField is the form field MethodOverride reads the method from
+const MethodOverrideField = "_method"
+
+// MethodOverride returns a PreDispatch hook that lets POST requests stand
+// in for PUT, PATCH and DELETE, for HTML forms and clients that can only
+// send GET and POST. The method is taken from the X-HTTP-Method-Override
+// header, or else from a _method field in a form body:
+//
+//	r.PreDispatch = append(r.PreDispatch, router.MethodOverride())
+//	r.Delete("/posts/:id", destroyPost)
+//
+//	<form method="POST" action="/posts/5">
+//	    <input type="hidden" name="_method" value="DELETE">
+//	</form>
+//
+// It has to be a hook rather than middleware because it changes which
+// route the request matches. Other methods, and overrides to anything but
+// PUT, PATCH or DELETE, are left unchanged.
+func MethodOverride() func(*http.Request) {
+	return func(req *http.Request) {
+		if req.Method != http.MethodPost {
+			return
+		}
+
+		method := req.Header.Get(MethodOverrideHeader)
+		if method == "" && isForm(req.Header.Get("Content-Type")) {
+			method = req.PostFormValue(MethodOverrideField)
+		}
+
+		switch method = strings.ToUpper(method); method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			req.Method = method
+		}
+	}
+}
+
+// isForm reports whether a Content-Type is a form encoding
+func isForm(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newMethodOverrideRouter() *Router {
+	r := New()
+	r.PreDispatch = append(r.PreDispatch, MethodOverride())
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		r.handle(method, "/posts/:id", func(c *Context) error {
+			return c.String(http.StatusOK, "%s %s title=%s", method, c.Param("id"), c.FormValue("title"))
+		}, "")
+	}
+	return r
+}
+
+func TestMethodOverrideFormField(t *testing.T) {
+	r := newMethodOverrideRouter()
+
+	form := url.Values{"_method": {"DELETE"}}
+	req := httptest.NewRequest("POST", "/posts/5", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "DELETE 5 title=" {
+		t.Errorf("expected the DELETE route, got %d %q", w.Code, w.Body.String())
+	}
+
+	// The rest of the form is still available to the handler
+	form = url.Values{"_method": {"patch"}, "title": {"Hello"}}
+	req = httptest.NewRequest("POST", "/posts/5", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "PATCH 5 title=Hello" {
+		t.Errorf("expected the PATCH route with the form, got %q", w.Body.String())
+	}
+}
+
+func TestMethodOverrideHeader(t *testing.T) {
+	r := newMethodOverrideRouter()
+
+	req := httptest.NewRequest("POST", "/posts/5", nil)
+	req.Header.Set("X-HTTP-Method-Override", "PUT")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "PUT 5 title=" {
+		t.Errorf("expected the PUT route, got %q", w.Body.String())
+	}
+}
+
+func TestMethodOverrideIgnored(t *testing.T) {
+	r := newMethodOverrideRouter()
+
+	tests := []struct {
+		name     string
+		method   string
+		override string
+		expected string
+	}{
+		{"only POST is overridden", "GET", "DELETE", "GET 5 title="},
+		{"only PUT, PATCH and DELETE are allowed", "POST", "GET", "POST 5 title="},
+		{"unknown method", "POST", "PURGE", "POST 5 title="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/posts/5", nil)
+			req.Header.Set("X-HTTP-Method-Override", tt.override)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Body.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPreDispatchRunsBeforeRouting(t *testing.T) {
+	r := New()
+	r.PreDispatch = append(r.PreDispatch, func(req *http.Request) {
+		req.URL.Path = strings.TrimPrefix(req.URL.Path, "/legacy")
+	})
+	r.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	req := httptest.NewRequest("GET", "/legacy/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "users" {
+		t.Errorf("expected the rewritten path to match, got %d %q", w.Code, w.Body.String())
+	}
+}
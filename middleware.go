@@ -1,5 +1,10 @@
 package router
 
+import (
+	"reflect"
+	"runtime"
+)
+
 // MiddlewareFunc is the function signature for middleware.
 // Middleware wraps a HandlerFunc and can perform actions before and/or after
 // the handler executes. Multiple middleware are chained together, with each
@@ -27,3 +32,66 @@ package router
 //	api := r.Group("/api", authMiddleware)        // Group
 //	r.Get("/users", handler, WithMiddleware(mw))  // Route-specific
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
+
+// RouteMiddleware reports the middleware that would run for a request,
+// in execution order: global, then group, then route-specific.
+// It returns nil if no route matches the method and path.
+//
+// Middleware functions have no names of their own, so each entry is the
+// name of the Go function that produced it, as reported by the runtime.
+// Named functions keep their name (e.g. "main.requireAuth"), while
+// closures are named after their enclosing function (e.g. "main.Logger.func1"):
+//
+//	r.Use(logging)
+//	api := r.Group("/api", requireAuth)
+//	api.Get("/users", listUsers)
+//
+//	r.RouteMiddleware("GET", "/api/users") // ["main.logging", "main.requireAuth"]
+//
+// For paths with routes registered WithHost, it reports the route used for
+// requests to no particular host; see HostRouteMiddleware.
+func (r *Router) RouteMiddleware(method, path string) []string {
+	return r.HostRouteMiddleware(method, "", path)
+}
+
+// HostRouteMiddleware is RouteMiddleware for a request to host, which
+// selects among the routes registered WithHost at the path:
+//
+//	admin := r.Group("/admin", requireAuth)
+//	admin.Get("/users", listUsers, WithHost("admin.example.com"))
+//
+//	r.HostRouteMiddleware("GET", "admin.example.com", "/admin/users") // ["main.requireAuth"]
+//	r.HostRouteMiddleware("GET", "www.example.com", "/admin/users")   // nil
+func (r *Router) HostRouteMiddleware(method, host, path string) []string {
+	n, _ := r.tree.Lookup(method, path)
+	if n == nil || n.Handlers[method] == nil {
+		return nil
+	}
+
+	middlewareList := n.Middleware
+	if sw := r.hosts[routeKey(method, n.Pattern)]; sw != nil {
+		route := sw.match(requestHost(host))
+		if route == nil {
+			return nil
+		}
+		middlewareList = route.middleware
+	}
+
+	chain := make([]MiddlewareFunc, 0, len(r.middleware)+len(middlewareList))
+	chain = append(chain, r.middleware...)
+	chain = append(chain, resolveMiddleware(middlewareList)...)
+
+	names := make([]string, len(chain))
+	for i, mw := range chain {
+		names[i] = middlewareName(mw)
+	}
+	return names
+}
+
+// middlewareName returns the name of the function behind a middleware
+func middlewareName(mw MiddlewareFunc) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
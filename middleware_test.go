@@ -0,0 +1,94 @@
+package router
+
+import (
+	"strings"
+	"testing"
+)
+
+func loggingTestMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+func authTestMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+func cacheTestMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	r.Use(loggingTestMiddleware)
+	api := r.Group("/api", authTestMiddleware)
+	api.Get("/users", handler, WithMiddleware(cacheTestMiddleware))
+	r.Get("/public", handler)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected []string
+	}{
+		{"GET", "/api/users", []string{"loggingTestMiddleware", "authTestMiddleware", "cacheTestMiddleware"}},
+		{"GET", "/public", []string{"loggingTestMiddleware"}},
+	}
+
+	for _, tt := range tests {
+		names := r.RouteMiddleware(tt.method, tt.path)
+		if len(names) != len(tt.expected) {
+			t.Errorf("%s %s: expected %d middleware, got %v", tt.method, tt.path, len(tt.expected), names)
+			continue
+		}
+		for i, want := range tt.expected {
+			if !strings.HasSuffix(names[i], "."+want) {
+				t.Errorf("%s %s: middleware %d: expected %s, got %s", tt.method, tt.path, i, want, names[i])
+			}
+		}
+	}
+
+	if names := r.RouteMiddleware("POST", "/api/users"); names != nil {
+		t.Errorf("expected nil for unmatched route, got %v", names)
+	}
+}
+
+func TestRouteMiddlewareHostRoutes(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	r.Use(loggingTestMiddleware)
+	admin := r.Group("/admin", authTestMiddleware)
+	admin.Get("/users", handler, WithHost("admin.example.com"), WithMiddleware(cacheTestMiddleware))
+	admin.Get("/reports", handler, WithHost("admin.example.com"))
+	admin.Get("/reports", handler)
+
+	tests := []struct {
+		host     string
+		path     string
+		expected []string
+	}{
+		{"admin.example.com", "/admin/users", []string{"loggingTestMiddleware", "authTestMiddleware", "cacheTestMiddleware"}},
+		{"admin.example.com:8080", "/admin/users", []string{"loggingTestMiddleware", "authTestMiddleware", "cacheTestMiddleware"}},
+		{"www.example.com", "/admin/users", nil},
+		{"", "/admin/users", nil},
+		{"www.example.com", "/admin/reports", []string{"loggingTestMiddleware", "authTestMiddleware"}},
+	}
+
+	for _, tt := range tests {
+		names := r.HostRouteMiddleware("GET", tt.host, tt.path)
+		if len(names) != len(tt.expected) || (names == nil) != (tt.expected == nil) {
+			t.Errorf("%s %s: expected %v, got %v", tt.host, tt.path, tt.expected, names)
+			continue
+		}
+		for i, want := range tt.expected {
+			if !strings.HasSuffix(names[i], "."+want) {
+				t.Errorf("%s %s: middleware %d: expected %s, got %s", tt.host, tt.path, i, want, names[i])
+			}
+		}
+	}
+
+	if names := r.RouteMiddleware("GET", "/admin/reports"); len(names) != 2 {
+		t.Errorf("expected the unscoped route's middleware, got %v", names)
+	}
+}
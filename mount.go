@@ -0,0 +1,45 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// allMethods lists the methods a mounted handler receives
+var allMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// Mount delegates every request under prefix, for every standard method,
+// to h. The prefix is stripped from the request path before h is called, as
+// by http.StripPrefix, so h sees paths relative to where it is mounted.
+// Global middleware runs before h like for any other route.
+//
+// Example:
+//
+//	r.Mount("/static", http.FileServer(http.Dir("./public")))
+//	r.Mount("/debug/pprof", pprofMux)
+//
+// Mounted routes are not named.
+func (r *Router) Mount(prefix string, h http.Handler) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	stripped := http.StripPrefix(prefix, h)
+
+	handler := func(c *Context) error {
+		stripped.ServeHTTP(c.Writer, c.Request)
+		return nil
+	}
+
+	for _, method := range allMethods {
+		r.addRoute(nil, method, prefix+"/", handler, &routeConfig{})
+		r.addRoute(nil, method, prefix+"/*path", handler, &routeConfig{})
+	}
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMountFileServer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.js"), []byte("console.log(1)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+
+	middlewareCalled := false
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			middlewareCalled = true
+			return next(c)
+		}
+	})
+	r.Mount("/static", http.FileServer(http.Dir(root)))
+
+	req := httptest.NewRequest("GET", "/static/app.js", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "console.log(1)" {
+		t.Errorf("expected file contents, got %q", w.Body.String())
+	}
+	if !middlewareCalled {
+		t.Error("expected global middleware to run for mounted handlers")
+	}
+
+	req = httptest.NewRequest("GET", "/static/missing.js", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 from the file server, got %d", w.Code)
+	}
+}
+
+func TestMountStripsPrefix(t *testing.T) {
+	r := New()
+	r.Mount("/api/legacy/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.Method+" "+req.URL.Path)
+	}))
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/api/legacy/users/1", "GET /users/1"},
+		{"DELETE", "/api/legacy/users/1", "DELETE /users/1"},
+		{"GET", "/api/legacy/", "GET /"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != tt.expected {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.path, tt.expected, w.Body.String())
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package router
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -160,3 +165,393 @@ func TestNamedRoutesAllMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestNamedRoutesHeadAsGet(t *testing.T) {
+	r := New()
+	r.HeadAsGet = true
+
+	handler := func(c *Context) error { return nil }
+	r.Get("/items/:id", handler)
+	r.Head("/items/:id", handler)
+	r.Head("/items/:id/meta", handler)
+	r.Head("/status", handler, WithName("status_head"))
+
+	namedRoutes := r.NamedRoutes()
+
+	route := namedRoutes["items_show"]
+	if route == nil {
+		t.Fatal("items_show route not registered")
+	}
+	if route.Method != "GET" {
+		t.Errorf("expected items_show to stay on GET, got %s", route.Method)
+	}
+
+	for name, route := range namedRoutes {
+		if route.Method == "HEAD" && name != "status_head" {
+			t.Errorf("unexpected auto-named HEAD route %s", name)
+		}
+	}
+
+	if namedRoutes["status_head"] == nil {
+		t.Error("explicitly named HEAD route should still be registered")
+	}
+}
+
+func TestNamedRoutesHeadOverwritesGetByDefault(t *testing.T) {
+	r := New()
+
+	handler := func(c *Context) error { return nil }
+	r.Get("/items", handler)
+	r.Head("/items", handler)
+
+	if route := r.NamedRoutes()["items_index"]; route == nil || route.Method != "HEAD" {
+		t.Errorf("expected items_index to be taken by the HEAD route without HeadAsGet")
+	}
+}
+
+func TestGetAllRegistersEveryPath(t *testing.T) {
+	r := New()
+
+	calls := 0
+	handler := func(c *Context) error {
+		calls++
+		return c.String(http.StatusOK, "home")
+	}
+
+	r.GetAll([]string{"/", "/home", "/index.html"}, handler, WithName("home"))
+
+	api := r.Group("/api")
+	api.GetAll([]string{"/status", "/health"}, handler)
+
+	for _, path := range []string{"/", "/home", "/index.html", "/api/status", "/api/health"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: expected status 200, got %d", path, w.Code)
+		}
+	}
+
+	if calls != 5 {
+		t.Errorf("expected handler to be called 5 times, got %d", calls)
+	}
+
+	namedRoutes := r.NamedRoutes()
+	if len(namedRoutes) != 2 {
+		t.Errorf("expected only canonical paths to be named, got %d routes", len(namedRoutes))
+	}
+	if route := namedRoutes["home"]; route == nil || route.Pattern != "/" {
+		t.Errorf("expected home to point at the canonical path /")
+	}
+	if route := namedRoutes["api_status_index"]; route == nil || route.Pattern != "/api/status" {
+		t.Errorf("expected api_status_index to point at /api/status")
+	}
+}
+
+func TestGetAllPanicsWithoutPaths(t *testing.T) {
+	r := New()
+
+	defer func() {
+		if rec := recover(); rec == nil {
+			t.Error("expected panic when no paths are given")
+		}
+	}()
+
+	r.GetAll(nil, func(c *Context) error { return nil })
+}
+
+func TestNamedRoutesInVersionedGroups(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return c.String(http.StatusOK, "OK") }
+
+	api := r.Group("/api")
+	v1 := api.Version("v1")
+	v2 := api.Version("v2")
+
+	v1.Get("/users/:id", handler, WithName("user_show"))
+	v2.Get("/users/:id", handler, WithName("user_show"))
+	v1.Get("/status", handler)
+	v2.Group("/admin").Get("/stats", handler, WithName("stats"))
+	v1.Resources("/posts", &TestController{}, Only(IndexAction))
+	api.Get("/ping", handler, WithName("ping"))
+
+	tests := []struct {
+		name    string
+		pattern string
+		version string
+	}{
+		{"v1_user_show", "/api/v1/users/:id", "v1"},
+		{"v2_user_show", "/api/v2/users/:id", "v2"},
+		{"api_v1_status_index", "/api/v1/status", "v1"},
+		{"v2_stats", "/api/v2/admin/stats", "v2"},
+		{"v1_posts_index", "/api/v1/posts", "v1"},
+		{"ping", "/api/ping", ""},
+	}
+
+	namedRoutes := r.NamedRoutes()
+	for _, tt := range tests {
+		route := namedRoutes[tt.name]
+		if route == nil {
+			t.Errorf("route %s not registered", tt.name)
+			continue
+		}
+		if route.Pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.name, tt.pattern, route.Pattern)
+		}
+		if route.Version != tt.version {
+			t.Errorf("%s: expected version %q, got %q", tt.name, tt.version, route.Version)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/v2/users/5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 for versioned route, got %d", w.Code)
+	}
+}
+
+func TestURLFor(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	r.Get("/users/:id", handler, WithName("user_show"))
+	r.Get("/users/:user_id/posts/:post_id", handler, WithName("user_post"))
+	r.Get("/files/*filepath", handler, WithName("file_show"))
+	r.Get("/about", handler, WithName("about"))
+	r.Get("/posts/:id/:slug?", handler, WithName("post_show"))
+
+	tests := []struct {
+		name     string
+		params   []string
+		expected string
+	}{
+		{"user_show", []string{"42"}, "/users/42"},
+		{"user_post", []string{"7", "99"}, "/users/7/posts/99"},
+		{"user_show", []string{"a b/c"}, "/users/a%20b%2Fc"},
+		{"file_show", []string{"docs/readme.md"}, "/files/docs/readme.md"},
+		{"about", nil, "/about"},
+		{"post_show", []string{"5", "hello"}, "/posts/5/hello"},
+		{"post_show", []string{"5"}, "/posts/5"},
+		{"post_show", []string{"5", ""}, "/posts/5"},
+	}
+
+	for _, tt := range tests {
+		got, err := r.URLFor(tt.name, tt.params...)
+		if err != nil {
+			t.Errorf("URLFor(%s, %v) failed: %v", tt.name, tt.params, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("URLFor(%s, %v): expected %s, got %s", tt.name, tt.params, tt.expected, got)
+		}
+	}
+
+	if _, err := r.URLFor("user_post", "7"); err == nil {
+		t.Error("expected an error for too few params")
+	}
+	if _, err := r.URLFor("user_show", "1", "2"); err == nil {
+		t.Error("expected an error for too many params")
+	}
+	if _, err := r.URLFor("missing"); err == nil {
+		t.Error("expected an error for an unknown route name")
+	}
+}
+
+func TestNameGenerator(t *testing.T) {
+	r := New()
+	r.NameGenerator = func(path, method string) string {
+		if path == "/internal" {
+			return ""
+		}
+		return method + " " + path
+	}
+
+	handler := func(c *Context) error { return nil }
+	r.Get("/users/:id", handler)
+	r.Post("/users", handler)
+	r.Get("/about", handler, WithName("about"))
+	r.Get("/internal", handler)
+
+	namedRoutes := r.NamedRoutes()
+
+	for _, name := range []string{"GET /users/:id", "POST /users", "about"} {
+		if namedRoutes[name] == nil {
+			t.Errorf("route %q not registered", name)
+		}
+	}
+	if len(namedRoutes) != 3 {
+		t.Errorf("expected 3 named routes, got %d", len(namedRoutes))
+	}
+	if namedRoutes["users_show"] != nil {
+		t.Error("expected the default generator not to be used")
+	}
+}
+
+func TestNamedRouteCollisionPanics(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	r.Get("/users/:id", handler, WithName("user"))
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected panic for a name used by another route")
+		}
+		msg := fmt.Sprint(rec)
+		if !strings.Contains(msg, `"user"`) || !strings.Contains(msg, "GET /users/:id") || !strings.Contains(msg, "GET /accounts/:id") {
+			t.Errorf("expected panic to name the collision, got %q", msg)
+		}
+	}()
+
+	r.Get("/accounts/:id", handler, WithName("user"))
+}
+
+func TestNamedRouteSameRouteAgain(t *testing.T) {
+	r := New()
+	r.AllowOverride = true
+	handler := func(c *Context) error { return nil }
+
+	// Registering the same route under the same name is not a collision
+	r.Get("/users/:id", handler, WithName("user"))
+	r.Get("/users/:id", handler, WithName("user"))
+
+	if route := r.NamedRoutes()["user"]; route == nil || route.Pattern != "/users/:id" {
+		t.Errorf("expected the user route to stay registered, got %+v", route)
+	}
+}
+
+func TestGenerateRoutesIsDeterministic(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	for _, name := range []string{"users", "posts", "comments", "tags", "authors", "orders", "items", "carts"} {
+		r.Get("/"+name, handler)
+		r.Get("/"+name+"/:id", handler)
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first", "routes.go")
+	second := filepath.Join(dir, "second", "routes.go")
+
+	if err := r.GenerateRoutes("routes", first); err != nil {
+		t.Fatalf("GenerateRoutes failed: %v", err)
+	}
+	if err := r.GenerateRoutes("routes", second); err != nil {
+		t.Fatalf("GenerateRoutes failed: %v", err)
+	}
+
+	a, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("expected both runs to generate identical files")
+	}
+
+	// Helpers appear in name order
+	if i, j := bytes.Index(a, []byte("func AuthorsIndexPath")), bytes.Index(a, []byte("func UsersShowPath")); i < 0 || j < 0 || i > j {
+		t.Errorf("expected helpers sorted by name, got AuthorsIndexPath at %d and UsersShowPath at %d", i, j)
+	}
+}
+
+func TestGenerateRoutesForResources(t *testing.T) {
+	r := New()
+	r.Resources("/posts", &TestController{})
+	r.Resource("/profile", &TestController{}, Only(ShowAction, EditAction))
+
+	outputFile := filepath.Join(t.TempDir(), "routes.go")
+	if err := r.GenerateRoutes("routes", outputFile); err != nil {
+		t.Fatalf("GenerateRoutes failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Collection routes, including the static /new, take no parameters;
+	// member routes, including /:id/edit, take the id
+	expected := []string{
+		"func PostsIndexPath(query ...url.Values) string",
+		"func PostsNewPath(query ...url.Values) string",
+		"func PostsCreatePath(query ...url.Values) string",
+		"func PostsShowPath(id string, query ...url.Values) string",
+		"func PostsEditPath(id string, query ...url.Values) string",
+		"func PostsUpdatePath(id string, query ...url.Values) string",
+		"func PostsDeletePath(id string, query ...url.Values) string",
+		"func ProfileShowPath(query ...url.Values) string",
+		"func ProfileEditPath(query ...url.Values) string",
+	}
+	for _, want := range expected {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+}
+
+func TestNamedRoutesWithAliases(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/people/:id", handler, WithName("person_show"), WithAliases("user_show", "member_show"))
+	v2 := r.Group("/api").Version("v2")
+	v2.Get("/people", handler, WithName("people"), WithAliases("users"))
+
+	routes := r.NamedRoutes()
+	for _, name := range []string{"person_show", "user_show", "member_show"} {
+		route := routes[name]
+		if route == nil || route.Pattern != "/people/:id" || route.Method != "GET" {
+			t.Errorf("expected %s to name GET /people/:id, got %+v", name, route)
+		}
+	}
+	if route := routes["user_show"]; route == nil || route.AliasOf != "person_show" {
+		t.Errorf("expected user_show to be an alias of person_show, got %+v", route)
+	}
+	if route := routes["v2_users"]; route == nil || route.Pattern != "/api/v2/people" || route.Version != "v2" {
+		t.Errorf("expected a versioned alias, got %+v", route)
+	}
+
+	if url, err := r.URLFor("user_show", "5"); err != nil || url != "/people/5" {
+		t.Errorf("expected /people/5, got %q (%v)", url, err)
+	}
+
+	// Walk reports the primary name
+	r.Walk(func(method, pattern string, route *Route) {
+		if pattern == "/people/:id" && (route == nil || route.Name != "person_show") {
+			t.Errorf("expected Walk to pass person_show, got %+v", route)
+		}
+	})
+
+	outputFile := filepath.Join(t.TempDir(), "routes.go")
+	if err := r.GenerateRoutes("routes", outputFile); err != nil {
+		t.Fatalf("GenerateRoutes failed: %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"func PersonShowPath(id string", "func UserShowPath(id string", "func MemberShowPath(id string"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+}
+
+func TestNamedRouteAliasCollisionPanics(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/users", handler, WithName("users"))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an alias taken by another route to panic")
+		}
+	}()
+	r.Get("/people", handler, WithAliases("users"))
+}
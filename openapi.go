@@ -0,0 +1,162 @@
+package router
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// OpenAPIInfo describes the API in the info section of the document built
+// by OpenAPI
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// openAPIDocument is the root of an OpenAPI 3.0 document
+type openAPIDocument struct {
+	OpenAPI string                                 `json:"openapi"`
+	Info    OpenAPIInfo                            `json:"info"`
+	Paths   map[string]map[string]openAPIOperation `json:"paths"`
+}
+
+// openAPIOperation describes one method on a path
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+// openAPIParameter describes a path parameter
+type openAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+// openAPISchema is the schema of a path parameter
+type openAPISchema struct {
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+}
+
+// openAPIResponse describes a response
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// openAPIMethods are the methods an OpenAPI 3.0 path item can describe
+var openAPIMethods = map[string]bool{
+	"GET": true, "PUT": true, "POST": true, "DELETE": true,
+	"OPTIONS": true, "HEAD": true, "PATCH": true, "TRACE": true,
+}
+
+// OpenAPI builds a minimal OpenAPI 3.0 document, as JSON, listing every
+// registered route. Each route becomes an operation on its path, with
+// :param and *wildcard segments written as {param}, and with the route
+// name, if it has one, as its operationId:
+//
+//	spec, err := r.OpenAPI(router.OpenAPIInfo{Title: "Blog API", Version: "1.0.0"})
+//
+// Path parameters are listed as strings, or as integers for :param(int)
+// and uuids for :param(uuid). A route ending in an optional :param? is
+// listed both with and without the parameter; without it, the operationId
+// gains a "_without_<param>" suffix to stay unique. Request and response schemas
+// are left for the application to fill in; every operation has a single
+// default response.
+func (r *Router) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]openAPIOperation),
+	}
+
+	r.Walk(func(method, pattern string, route *Route) {
+		if !openAPIMethods[method] {
+			return
+		}
+
+		op := openAPIOperation{
+			Responses: map[string]openAPIResponse{
+				"default": {Description: "Default response"},
+			},
+		}
+		if route != nil {
+			op.OperationID = route.Name
+		}
+
+		for _, path := range openAPIPaths(pattern) {
+			pathOp := op
+			pathOp.Parameters = path.params
+			if path.without != "" && op.OperationID != "" {
+				// operationIds must be unique
+				pathOp.OperationID += "_without_" + path.without
+			}
+
+			if doc.Paths[path.path] == nil {
+				doc.Paths[path.path] = make(map[string]openAPIOperation)
+			}
+			doc.Paths[path.path][strings.ToLower(method)] = pathOp
+		}
+	})
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// openAPIPath is a route pattern converted to an OpenAPI path
+type openAPIPath struct {
+	path    string
+	params  []openAPIParameter
+	without string // optional param left out of the path, if any
+}
+
+// openAPIPaths converts a route pattern to OpenAPI paths: one, or two for a
+// pattern ending in an optional param
+func openAPIPaths(pattern string) []openAPIPath {
+	var path openAPIPath
+	var segments []string
+	var paths []openAPIPath
+
+	for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			segments = append(segments, segment)
+			continue
+		}
+
+		name := segment[1:]
+		optional := strings.HasSuffix(name, "?")
+		name = strings.TrimSuffix(name, "?")
+
+		schema := openAPISchema{Type: "string"}
+		if i := strings.IndexByte(name, '('); i >= 0 {
+			switch name[i+1 : len(name)-1] {
+			case "int":
+				schema = openAPISchema{Type: "integer"}
+			case "uuid":
+				schema.Format = "uuid"
+			}
+			name = name[:i]
+		}
+
+		if optional {
+			// The route also matches without the last segment
+			paths = append(paths, openAPIPath{
+				path:    "/" + strings.Join(segments, "/"),
+				params:  append([]openAPIParameter(nil), path.params...),
+				without: name,
+			})
+		}
+
+		segments = append(segments, "{"+name+"}")
+		path.params = append(path.params, openAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+
+	path.path = "/" + strings.Join(segments, "/")
+	return append(paths, path)
+}
@@ -0,0 +1,90 @@
+package router
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Resources("/posts", &TestController{}, Only(IndexAction, ShowAction, CreateAction))
+	r.Get("/users/:id(int)/posts/:post_id", handler, WithName("user_post"))
+	r.Get("/archive/:year?", handler, WithName("archive"))
+	r.GetAll([]string{"/about", "/about-us"}, handler)
+
+	spec, err := r.OpenAPI(OpenAPIInfo{Title: "Blog API", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("OpenAPI failed: %v", err)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name     string `json:"name"`
+				In       string `json:"in"`
+				Required bool   `json:"required"`
+				Schema   struct {
+					Type string `json:"type"`
+				} `json:"schema"`
+			} `json:"parameters"`
+			Responses map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, spec)
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "Blog API" || doc.Info.Version != "1.0.0" {
+		t.Errorf("unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+
+	operations := []struct {
+		path, method, operationID string
+	}{
+		{"/posts", "get", "posts_index"},
+		{"/posts", "post", "posts_create"},
+		{"/posts/{id}", "get", "posts_show"},
+		{"/users/{id}/posts/{post_id}", "get", "user_post"},
+		{"/archive", "get", "archive_without_year"},
+		{"/archive/{year}", "get", "archive"},
+		{"/about", "get", "about_index"},
+		{"/about-us", "get", ""},
+	}
+	for _, tt := range operations {
+		op, ok := doc.Paths[tt.path][tt.method]
+		if !ok {
+			t.Errorf("missing %s %s", tt.method, tt.path)
+			continue
+		}
+		if op.OperationID != tt.operationID {
+			t.Errorf("%s %s: expected operationId %q, got %q", tt.method, tt.path, tt.operationID, op.OperationID)
+		}
+		if len(op.Responses) == 0 {
+			t.Errorf("%s %s: expected a response", tt.method, tt.path)
+		}
+	}
+	if len(doc.Paths) != 7 {
+		t.Errorf("expected 7 paths, got %d", len(doc.Paths))
+	}
+
+	params := doc.Paths["/users/{id}/posts/{post_id}"]["get"].Parameters
+	if len(params) != 2 {
+		t.Fatalf("expected 2 parameters, got %+v", params)
+	}
+	if params[0].Name != "id" || params[0].In != "path" || !params[0].Required || params[0].Schema.Type != "integer" {
+		t.Errorf("unexpected id parameter: %+v", params[0])
+	}
+	if params[1].Name != "post_id" || params[1].Schema.Type != "string" {
+		t.Errorf("unexpected post_id parameter: %+v", params[1])
+	}
+	if params := doc.Paths["/archive"]["get"].Parameters; len(params) != 0 {
+		t.Errorf("expected no parameters without the optional segment, got %+v", params)
+	}
+}
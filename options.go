@@ -1,5 +1,7 @@
 package router
 
+import "time"
+
 // RouteOption is a functional option for configuring routes
 type RouteOption interface {
 	applyToRoute(*routeConfig)
@@ -9,6 +11,10 @@ type RouteOption interface {
 type routeConfig struct {
 	name       string
 	middleware []MiddlewareFunc
+	host       string
+	timeout    time.Duration
+	aliases    []string
+	generated  bool // name was made up, as for resources, so a later route may take it
 }
 
 // routeName is an option that sets the route name
@@ -18,11 +24,31 @@ func (n routeName) applyToRoute(cfg *routeConfig) {
 	cfg.name = string(n)
 }
 
-// WithName sets the name for a route (for reverse routing and code generation)
+// WithName sets the name for a route (for reverse routing and code generation).
+// Registering panics if another route already has the name.
 func WithName(name string) RouteOption {
 	return routeName(name)
 }
 
+// routeAliases is an option that gives a route additional names
+type routeAliases []string
+
+func (a routeAliases) applyToRoute(cfg *routeConfig) {
+	cfg.aliases = append(cfg.aliases, a...)
+}
+
+// WithAliases gives a route additional names besides the one from WithName
+// or the generated one, for example to keep an old name working while
+// callers move to a new one:
+//
+//	r.Get("/people/:id", showPerson, WithName("person_show"), WithAliases("user_show"))
+//
+// Each alias can be used with URLFor and gets its own generated helper.
+// Registering panics if another route already has one of the names.
+func WithAliases(names ...string) RouteOption {
+	return routeAliases(names)
+}
+
 // routeMiddleware is an option that adds middleware to a route
 type routeMiddleware []MiddlewareFunc
 
@@ -35,11 +61,59 @@ func WithMiddleware(middleware ...MiddlewareFunc) RouteOption {
 	return routeMiddleware(middleware)
 }
 
+// routeHost is an option that restricts a route to a host
+type routeHost string
+
+func (h routeHost) applyToRoute(cfg *routeConfig) {
+	cfg.host = string(h)
+}
+
+// WithHost restricts a route to requests whose Host header matches host.
+// The host is matched case-insensitively and without the port. A leading
+// "*." matches any subdomain, so "*.example.com" matches "api.example.com"
+// and "a.b.example.com" but not "example.com".
+//
+// Several routes with the same method and path can be registered for
+// different hosts. Routes for an exact host are tried first, then wildcard
+// hosts in registration order, and finally the route registered at that
+// path without WithHost, if any. If none of them match the request's host,
+// the request is handled by NotFound:
+//
+//	r.Get("/", adminHome, WithHost("admin.example.com"))
+//	r.Get("/", tenantHome, WithHost("*.example.com"))
+//	r.Get("/", publicHome) // any other host
+//
+// The host only chooses between routes at the same path; it doesn't make a
+// request fall through to a different path that would also match.
+func WithHost(host string) RouteOption {
+	return routeHost(host)
+}
+
+// routeTimeout is an option that limits how long a handler may run
+type routeTimeout time.Duration
+
+func (d routeTimeout) applyToRoute(cfg *routeConfig) {
+	cfg.timeout = time.Duration(d)
+}
+
+// WithTimeout limits how long the route's handler may run. The handler's
+// request context is cancelled after d, and if the handler hasn't returned
+// by then the request fails with a 503 Service Unavailable StatusError.
+//
+// The handler runs in its own goroutine with its response buffered, so a
+// handler that times out sends nothing to the client; the buffered response
+// of a handler that finishes in time is sent when it returns. Route
+// middleware runs outside the timeout. Handlers should watch
+// c.Request.Context() and stop work once it is done.
+func WithTimeout(d time.Duration) RouteOption {
+	return routeTimeout(d)
+}
+
 // parseRouteOptions extracts configuration from route options
-func parseRouteOptions(opts []RouteOption) (string, []MiddlewareFunc) {
+func parseRouteOptions(opts []RouteOption) *routeConfig {
 	cfg := &routeConfig{}
 	for _, opt := range opts {
 		opt.applyToRoute(cfg)
 	}
-	return cfg.name, cfg.middleware
+	return cfg
 }
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Proxy forwards the current request to the upstream at target and streams
+// the upstream response back to the client. The method, body and headers
+// are forwarded, except hop-by-hop headers such as Connection, and
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto are set.
+//
+// If the route ends in a *wildcard segment, the captured path is appended
+// to the target's path; otherwise the request goes to the target's path
+// unchanged. The captured path is cleaned, so it can't reach above the
+// target's path. The request's query string is appended to any query in target:
+//
+//	r.Get("/api/*path", func(c *Context) error {
+//	    return c.Proxy("http://backend:8080/v2")
+//	})
+//	// GET /api/users/42?page=2 -> GET http://backend:8080/v2/users/42?page=2
+//
+// If the upstream can't be reached, Proxy returns a 502 StatusError wrapping
+// the cause, which the router passes to the ErrorHandler. Upstream error
+// responses such as a 500 are passed through to the client unchanged.
+func (c *Context) Proxy(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid proxy target %q: %w", target, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid proxy target %q: must be an absolute URL", target)
+	}
+
+	rest := c.proxyPath()
+
+	var proxyErr error
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = u.Scheme
+			pr.Out.URL.Host = u.Host
+			pr.Out.URL.Path = joinProxyPath(u.Path, rest)
+			pr.Out.URL.RawPath = ""
+			pr.Out.URL.RawQuery = joinQuery(u.RawQuery, pr.In.URL.RawQuery)
+			pr.Out.Host = ""
+			pr.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			proxyErr = err
+		},
+	}
+
+	proxy.ServeHTTP(c.Writer, c.Request)
+
+	if proxyErr != nil {
+		return &StatusError{
+			Code:    http.StatusBadGateway,
+			Message: http.StatusText(http.StatusBadGateway),
+			Err:     proxyErr,
+		}
+	}
+	return nil
+}
+
+// proxyPath returns the part of the request path captured by the route's
+// trailing wildcard, or "" if the route doesn't end in one
+func (c *Context) proxyPath() string {
+	i := strings.LastIndex(c.pattern, "/")
+	if i < 0 || !strings.HasPrefix(c.pattern[i+1:], "*") {
+		return ""
+	}
+
+	rest := c.Params[c.pattern[i+2:]]
+	if rest != "" && strings.HasSuffix(c.Request.URL.Path, "/") {
+		rest += "/"
+	}
+	return rest
+}
+
+// joinProxyPath appends rest to the target path with a single slash. rest
+// is cleaned first, so that ".." segments, which may have been sent encoded
+// as %2F, can't climb out of the target path.
+func joinProxyPath(base, rest string) string {
+	if rest == "" {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+
+	cleaned := path.Clean("/" + rest)
+	if cleaned != "/" && strings.HasSuffix(rest, "/") {
+		cleaned += "/"
+	}
+	return strings.TrimSuffix(base, "/") + cleaned
+}
+
+// joinQuery combines the target's query string with the request's
+func joinQuery(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "&" + b
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxyWildcardPath(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("X-Upstream", "yes")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, req.Method+" "+req.URL.RequestURI()+" "+string(body)+" "+req.Header.Get("X-Token")+" "+req.Header.Get("Connection"))
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Post("/api/*path", func(c *Context) error {
+		return c.Proxy(upstream.URL + "/v2?key=abc")
+	})
+
+	req := httptest.NewRequest("POST", "/api/users/42?page=2", strings.NewReader("hello"))
+	req.Header.Set("X-Token", "secret")
+	req.Header.Set("Connection", "close")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected upstream status 201, got %d", w.Code)
+	}
+	if w.Header().Get("X-Upstream") != "yes" {
+		t.Error("expected upstream response headers to be copied")
+	}
+
+	expected := "POST /v2/users/42?key=abc&page=2 hello secret "
+	if w.Body.String() != expected {
+		t.Errorf("expected body %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestProxyWildcardPathCannotEscapeTarget(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.URL.Path)
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Get("/api/*path", func(c *Context) error {
+		return c.Proxy(upstream.URL + "/v2")
+	})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/api/..%2F..%2Fadmin", "/v2/admin"},
+		{"/api/users/..%2F..%2F..%2Fadmin/", "/v2/admin/"},
+		{"/api/users/..%2Fposts", "/v2/posts"},
+		{"/api/..%2F", "/v2/"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Body.String() != tt.expected {
+			t.Errorf("GET %s: expected upstream path %q, got %q", tt.path, tt.expected, w.Body.String())
+		}
+	}
+}
+
+func TestProxyWithoutWildcard(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.URL.Path)
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Get("/status/:id", func(c *Context) error {
+		return c.Proxy(upstream.URL + "/health")
+	})
+
+	req := httptest.NewRequest("GET", "/status/1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "/health" {
+		t.Errorf("expected request to go to the target path, got %q", w.Body.String())
+	}
+}
+
+func TestProxyUpstreamError(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	target := upstream.URL
+	upstream.Close()
+
+	r := New()
+
+	var handlerErr error
+	r.ErrorHandler = func(c *Context, err error) {
+		handlerErr = err
+		c.JSON(statusCode(err), map[string]string{"error": err.Error()})
+	}
+	r.Get("/*path", func(c *Context) error {
+		return c.Proxy(target)
+	})
+
+	req := httptest.NewRequest("GET", "/anything", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", w.Code)
+	}
+
+	var se *StatusError
+	if !errors.As(handlerErr, &se) || se.Err == nil {
+		t.Errorf("expected a StatusError wrapping the upstream error, got %v", handlerErr)
+	}
+}
+
+func TestProxyInvalidTarget(t *testing.T) {
+	r := New()
+	r.Get("/", func(c *Context) error {
+		return c.Proxy("/relative")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+)
+
+// Renderer renders named templates for Context.Render and Context.RenderStream.
+// Set Router.Renderer to enable template rendering.
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}) error
+}
+
+// TemplateRenderer is a Renderer backed by html/template. Templates are
+// looked up by name, as with template.ExecuteTemplate.
+type TemplateRenderer struct {
+	templates *template.Template
+}
+
+// NewTemplateRenderer creates a Renderer for a parsed set of templates:
+//
+//	tmpl := template.Must(template.ParseGlob("templates/*.html"))
+//	r.Renderer = router.NewTemplateRenderer(tmpl)
+func NewTemplateRenderer(templates *template.Template) *TemplateRenderer {
+	return &TemplateRenderer{templates: templates}
+}
+
+// ParseTemplates parses the template files matching pattern, as with
+// template.ParseGlob, and returns a Renderer for them. Each template is
+// named after its file's base name, such as "index.html".
+func ParseTemplates(pattern string) (*TemplateRenderer, error) {
+	templates, err := template.ParseGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplateRenderer(templates), nil
+}
+
+// Render executes the named template with data
+func (tr *TemplateRenderer) Render(w io.Writer, name string, data interface{}) error {
+	return tr.templates.ExecuteTemplate(w, name, data)
+}
+
+// Render renders a template into a buffer and sends it as an HTML response.
+// Because the output is buffered, a template error leaves the response
+// untouched and is returned so the ErrorHandler can send a proper error.
+//
+// For very large pages, see RenderStream.
+func (c *Context) Render(status int, name string, data interface{}) error {
+	renderer, err := c.renderer()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, name, data); err != nil {
+		return err
+	}
+	return c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// RenderStream renders a template directly to the response without buffering.
+//
+// The status and headers are sent when the template writes its first byte.
+// If the template fails before that, the error is returned as with Render.
+// If it fails mid-stream, the status can no longer be changed, so the error
+// is logged and the partial response is left as is.
+//
+// Prefer Render unless the page is large enough that buffering it is wasteful.
+func (c *Context) RenderStream(status int, name string, data interface{}) error {
+	renderer, err := c.renderer()
+	if err != nil {
+		return err
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w := &statusWriter{w: c.Writer, status: status}
+	if err := renderer.Render(w, name, data); err != nil {
+		if !c.IsHeaderWritten() {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Error rendering template %q after headers sent: %v\n", name, err)
+		return nil
+	}
+
+	// Templates that render nothing still need a status
+	c.Writer.WriteHeader(status)
+	return nil
+}
+
+// renderer returns the router's Renderer or an error if none is configured
+func (c *Context) renderer() (Renderer, error) {
+	if c.router == nil || c.router.Renderer == nil {
+		return nil, fmt.Errorf("no renderer configured: set Router.Renderer to render templates")
+	}
+	return c.router.Renderer, nil
+}
+
+// statusWriter writes the given status before the first byte of the body
+type statusWriter struct {
+	w      *responseWriter
+	status int
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	s.w.WriteHeader(s.status)
+	return s.w.Write(b)
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRenderer renders templates from a map of functions
+type testRenderer map[string]func(w io.Writer, data interface{}) error
+
+func (tr testRenderer) Render(w io.Writer, name string, data interface{}) error {
+	tmpl, ok := tr[name]
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+	return tmpl(w, data)
+}
+
+func newRenderRouter() *Router {
+	r := New()
+	r.ErrorHandler = func(c *Context, err error) {
+		if !c.IsHeaderWritten() {
+			c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+	r.Renderer = testRenderer{
+		"hello": func(w io.Writer, data interface{}) error {
+			_, err := fmt.Fprintf(w, "<h1>Hello, %v</h1>", data)
+			return err
+		},
+		"broken": func(w io.Writer, data interface{}) error {
+			return errors.New("early failure")
+		},
+		"partial": func(w io.Writer, data interface{}) error {
+			io.WriteString(w, "<h1>Report</h1>")
+			return errors.New("late failure")
+		},
+	}
+	return r
+}
+
+func TestRender(t *testing.T) {
+	r := newRenderRouter()
+	r.Get("/hello", func(c *Context) error {
+		return c.Render(http.StatusCreated, "hello", "world")
+	})
+	r.Get("/partial", func(c *Context) error {
+		return c.Render(http.StatusOK, "partial", nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+	if w.Body.String() != "<h1>Hello, world</h1>" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	// Buffered rendering discards partial output on error
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/partial", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "Report") {
+		t.Errorf("expected partial output to be discarded, got %q", w.Body.String())
+	}
+}
+
+func TestRenderStream(t *testing.T) {
+	r := newRenderRouter()
+	r.Get("/:name", func(c *Context) error {
+		return c.RenderStream(http.StatusOK, c.Param("name"), "stream")
+	})
+
+	tests := []struct {
+		name string
+		code int
+		body string
+	}{
+		{"hello", http.StatusOK, "<h1>Hello, stream</h1>"},
+		{"broken", http.StatusInternalServerError, `{"error":"early failure"}`},
+		{"partial", http.StatusOK, "<h1>Report</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/"+tt.name, nil))
+
+			if w.Code != tt.code {
+				t.Errorf("expected status %d, got %d", tt.code, w.Code)
+			}
+			if strings.TrimSpace(w.Body.String()) != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRenderWithoutRenderer(t *testing.T) {
+	r := New()
+	r.Get("/", func(c *Context) error {
+		return c.Render(http.StatusOK, "index", nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "no renderer configured") {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	tmpl := template.Must(template.New("user.html").Parse(`<h1>{{.Name}}</h1><p>{{.Bio}}</p>`))
+
+	r := New()
+	r.Renderer = NewTemplateRenderer(tmpl)
+	r.Get("/user", func(c *Context) error {
+		return c.Render(http.StatusOK, "user.html", map[string]string{
+			"Name": "Alice",
+			"Bio":  "<script>alert(1)</script>",
+		})
+	})
+	r.Get("/missing", func(c *Context) error {
+		return c.Render(http.StatusOK, "missing.html", nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/user", nil))
+
+	expected := "<h1>Alice</h1><p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Errorf("expected 200 %q, got %d %q", expected, w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 for an unknown template, got %d", w.Code)
+	}
+}
+
+func TestParseTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.html"), []byte("Hello, {{.}}!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := ParseTemplates(filepath.Join(dir, "*.html"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := renderer.Render(&buf, "hello.html", "world"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "Hello, world!" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	if _, err := ParseTemplates(filepath.Join(dir, "*.tmpl")); err == nil {
+		t.Error("expected an error when no files match")
+	}
+}
@@ -0,0 +1,83 @@
+package router
+
+// RequestIDHeader is the header RequestID reads and writes the request ID in
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest incoming request ID RequestID accepts
+const maxRequestIDLength = 128
+
+// RequestIDOption is a functional option for configuring RequestID
+type RequestIDOption func(*requestIDConfig)
+
+// requestIDConfig holds the configuration for RequestID
+type requestIDConfig struct {
+	generate func() string
+}
+
+// WithRequestIDGenerator sets the function that creates IDs for requests
+// that don't bring their own. The default generates 32 random hex
+// characters; tests can swap in a deterministic generator.
+func WithRequestIDGenerator(generate func() string) RequestIDOption {
+	return func(cfg *requestIDConfig) {
+		cfg.generate = generate
+	}
+}
+
+// requestIDStoreKey is the Context store key for the request ID
+const requestIDStoreKey = "router.request_id"
+
+// RequestID returns middleware that gives each request an ID for tracing
+// it through logs and services. The ID is taken from the request's
+// X-Request-ID header, or generated if the header is missing or invalid,
+// and is echoed in the X-Request-ID response header:
+//
+//	r.Use(router.RequestID())
+//
+//	r.Get("/orders/:id", func(c *router.Context) error {
+//	    log.Printf("request=%s", c.RequestID())
+//	    return nil
+//	})
+//
+// Incoming IDs longer than 128 characters, or containing anything but
+// printable ASCII, are replaced with a generated one.
+func RequestID(opts ...RequestIDOption) MiddlewareFunc {
+	cfg := &requestIDConfig{
+		generate: func() string { return randomHex(16) },
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			id := c.Request.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = cfg.generate()
+			}
+
+			c.Set(requestIDStoreKey, id)
+			c.Writer.Header().Set(RequestIDHeader, id)
+
+			return next(c)
+		}
+	}
+}
+
+// validRequestID reports whether an incoming request ID can be used as is
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns the request's ID, or "" if RequestID isn't in use
+func (c *Context) RequestID() string {
+	id, _ := c.store[requestIDStoreKey].(string)
+	return id
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	n := 0
+	r := New()
+	r.Use(RequestID(WithRequestIDGenerator(func() string {
+		n++
+		return fmt.Sprintf("req-%d", n)
+	})))
+	r.Get("/", func(c *Context) error {
+		return c.String(200, c.RequestID())
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		expected string
+	}{
+		{"generated", "", "req-1"},
+		{"incoming", "abc-123", "abc-123"},
+		{"invalid incoming", "bad id", "req-2"},
+		{"too long", strings.Repeat("a", 129), "req-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get(RequestIDHeader); got != tt.expected {
+				t.Errorf("expected response header %q, got %q", tt.expected, got)
+			}
+			if w.Body.String() != tt.expected {
+				t.Errorf("expected c.RequestID() %q, got %q", tt.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestIDDefaultGenerator(t *testing.T) {
+	r := New()
+	r.Use(RequestID())
+	r.Get("/", func(c *Context) error { return nil })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	if len(id) != 32 || !isLowerHex(id) {
+		t.Errorf("expected 32 hex characters, got %q", id)
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -54,6 +55,18 @@ type resourceConfig struct {
 	only       []ResourceAction
 	except     []ResourceAction
 	middleware []MiddlewareFunc
+	name       string
+	shallow    bool
+	singular   bool
+	custom     []customRoute
+	param      string
+}
+
+// customRoute is an extra member or collection route added to a resource
+type customRoute struct {
+	action string
+	method string
+	member bool
 }
 
 // resourceOnly is an option that limits actions to include
@@ -92,15 +105,123 @@ func WithResourceMiddleware(middleware ...MiddlewareFunc) ResourceOption {
 	return resourceMiddleware(middleware)
 }
 
+// resourceName is an option that overrides the name used for route names
+type resourceName string
+
+func (n resourceName) applyToResource(cfg *resourceConfig) {
+	cfg.name = string(n)
+}
+
+// WithResourceName sets the name used to build the resource's route names,
+// instead of the last segment of its path. This keeps names consistent when
+// the URL uses a different form of the word:
+//
+//	r.Resources("/user", &UserController{}, WithResourceName("users"))
+//	// names: users_index, users_show, ... (instead of user_index, ...)
+func WithResourceName(name string) ResourceOption {
+	return resourceName(name)
+}
+
+// resourceShallow is an option that moves member routes out of the nesting
+type resourceShallow struct{}
+
+func (resourceShallow) applyToResource(cfg *resourceConfig) {
+	cfg.shallow = true
+}
+
+// Shallow nests only the collection actions (index, new and create) under a
+// nested resource's path. The member actions (show, edit, update and
+// delete) are registered at the top level, since the member's id already
+// identifies it:
+//
+//	r.Resources("/posts/:post_id/comments", &CommentController{}, Shallow())
+//	// GET    /posts/:post_id/comments -> Index
+//	// POST   /posts/:post_id/comments -> Create
+//	// GET    /comments/:id            -> Show
+//	// DELETE /comments/:id            -> Delete
+//
+// In a group, the member routes keep the group's prefix.
+func Shallow() ResourceOption {
+	return resourceShallow{}
+}
+
+func (r customRoute) applyToResource(cfg *resourceConfig) {
+	cfg.custom = append(cfg.custom, r)
+}
+
+// Member adds a route for an action on a single member of the resource, at
+// the member's path followed by the action. The route calls the controller
+// method named after the action in CamelCase, which must have the signature
+// func(*Context) error, and is named after the resource and the action:
+//
+//	r.Resources("/posts", &PostController{}, Member("publish", "POST"))
+//	// POST /posts/:id/publish -> PostController.Publish, named "posts_publish"
+//
+// Registering panics if the controller has no such method.
+func Member(action, method string) ResourceOption {
+	return customRoute{action: action, method: strings.ToUpper(method), member: true}
+}
+
+// Collection adds a route for an action on the whole resource, at the
+// resource's path followed by the action. The controller method is found
+// as for Member:
+//
+//	r.Resources("/posts", &PostController{}, Collection("search", "GET"))
+//	// GET /posts/search -> PostController.Search, named "posts_search"
+func Collection(action, method string) ResourceOption {
+	return customRoute{action: action, method: strings.ToUpper(method)}
+}
+
+// resourceParam is an option that renames the member id parameter
+type resourceParam string
+
+func (p resourceParam) applyToResource(cfg *resourceConfig) {
+	cfg.param = string(p)
+}
+
+// ParamName sets the name of the parameter that identifies a member,
+// instead of "id". Naming it after the resource keeps it distinct from the
+// ids of resources nested beneath it, and in the arguments of the generated
+// route helpers:
+//
+//	r.Resources("/posts", &PostController{}, ParamName("post_id"))
+//	// GET /posts/:post_id -> Show, with c.Param("post_id")
+func ParamName(name string) ResourceOption {
+	return resourceParam(name)
+}
+
 // parseResourceOptions extracts configuration from resource options
 func parseResourceOptions(opts []ResourceOption) *resourceConfig {
-	cfg := &resourceConfig{}
+	cfg := &resourceConfig{param: "id"}
 	for _, opt := range opts {
 		opt.applyToResource(cfg)
 	}
+
+	// Catch typos, which would otherwise silently drop or keep routes
+	for _, actions := range [][]ResourceAction{cfg.only, cfg.except} {
+		for _, action := range actions {
+			if !isResourceAction(action) {
+				valid := make([]string, len(AllResourceActions))
+				for i, a := range AllResourceActions {
+					valid[i] = string(a)
+				}
+				panic(fmt.Sprintf("unknown resource action %q (valid actions are %s)", action, strings.Join(valid, ", ")))
+			}
+		}
+	}
 	return cfg
 }
 
+// isResourceAction reports whether action is one of AllResourceActions
+func isResourceAction(action ResourceAction) bool {
+	for _, a := range AllResourceActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
 // shouldIncludeAction determines if an action should be included based on Only/Except options
 func (cfg *resourceConfig) shouldIncludeAction(action ResourceAction) bool {
 	// If Only is specified, action must be in the list
@@ -134,18 +255,36 @@ type actionRoute struct {
 	action ResourceAction
 }
 
-// getResourceRoutes returns the route definitions for RESTful resources
+// getResourceRoutes returns the route definitions for RESTful resources,
+// with the collection actions at basePath and the member actions under
+// memberPath (the same path unless the resource is shallow), identified by
+// the param parameter
 // Order matters! Static routes (/new, /:id/edit) must come before dynamic routes (/:id)
-func getResourceRoutes(basePath string) []actionRoute {
+func getResourceRoutes(basePath, memberPath, param string) []actionRoute {
+	member := memberPath + "/:" + param
 	return []actionRoute{
 		{"GET", basePath, IndexAction},
 		{"GET", basePath + "/new", NewAction}, // Must be before /:id
 		{"POST", basePath, CreateAction},
-		{"GET", basePath + "/:id/edit", EditAction}, // Must be before /:id
-		{"GET", basePath + "/:id", ShowAction},
-		{"PATCH", basePath + "/:id", UpdateAction},
-		{"PUT", basePath + "/:id", UpdateAction}, // Also accept PUT for Update
-		{"DELETE", basePath + "/:id", DeleteAction},
+		{"GET", member + "/edit", EditAction}, // Must be before /:id
+		{"GET", member, ShowAction},
+		{"PATCH", member, UpdateAction},
+		{"PUT", member, UpdateAction}, // Also accept PUT for Update
+		{"DELETE", member, DeleteAction},
+	}
+}
+
+// getSingularResourceRoutes returns the route definitions for a singular
+// resource, which has no index action and no :id segment
+func getSingularResourceRoutes(basePath string) []actionRoute {
+	return []actionRoute{
+		{"GET", basePath + "/new", NewAction},
+		{"POST", basePath, CreateAction},
+		{"GET", basePath + "/edit", EditAction},
+		{"GET", basePath, ShowAction},
+		{"PATCH", basePath, UpdateAction},
+		{"PUT", basePath, UpdateAction}, // Also accept PUT for Update
+		{"DELETE", basePath, DeleteAction},
 	}
 }
 
@@ -155,19 +294,154 @@ func getResourceRoutes(basePath string) []actionRoute {
 //	r.Resources("/users", &UserController{})
 //	r.Resources("/posts", &PostController{}, Only(IndexAction, ShowAction))
 //	r.Resources("/comments", &CommentController{}, Except(NewAction, EditAction))
-func (r *Router) Resources(path string, controller Controller, opts ...ResourceOption) {
+//
+// The returned ResourceScope nests further resources under each member.
+func (r *Router) Resources(path string, controller Controller, opts ...ResourceOption) *ResourceScope {
 	config := parseResourceOptions(opts)
 
 	// If no Only/Except options are provided, validate that all methods are implemented
 	requireAll := len(config.only) == 0 && len(config.except) == 0
 
-	// Extract resource name from path (e.g., "/todos" -> "todos", "/api/v1/users" -> "users")
-	resourceName := path
-	if idx := strings.LastIndex(path, "/"); idx >= 0 {
-		resourceName = path[idx+1:]
+	r.registerResources(nil, path, path, controller, config, requireAll)
+	return newResourceScope(r, nil, path, config)
+}
+
+// Resource registers RESTful routes for a singular resource, one that the
+// client looks up without an id, such as the current user's profile. It
+// registers the same actions as Resources except Index, with no :id segment:
+//
+//	r.Resource("/profile", &ProfileController{})
+//	// GET    /profile/new  -> New
+//	// POST   /profile      -> Create
+//	// GET    /profile      -> Show
+//	// GET    /profile/edit -> Edit
+//	// PATCH  /profile      -> Update
+//	// DELETE /profile      -> Delete
+//
+// Without Only or Except, the controller must implement all of these
+// actions, but not Index.
+func (r *Router) Resource(path string, controller Controller, opts ...ResourceOption) *ResourceScope {
+	config := parseResourceOptions(opts)
+	config.singular = true
+
+	requireAll := len(config.only) == 0 && len(config.except) == 0
+
+	r.registerResources(nil, path, path, controller, config, requireAll)
+	return newResourceScope(r, nil, path, config)
+}
+
+// ResourceScope is returned when a resource is registered, for nesting
+// other resources beneath it
+type ResourceScope struct {
+	router     *Router
+	group      *Group // group the resource was registered on, if any
+	path       string // member path, relative to group
+	namePrefix string // for the names of nested resources, such as "post_"
+}
+
+// newResourceScope returns the scope for a resource registered at path.
+// Resources nest under a member, identified by the resource's ParamName,
+// or else by the singular of its name followed by "_id" (so "/posts" nests
+// under "/posts/:post_id"). Singular resources nest under their path.
+func newResourceScope(r *Router, g *Group, path string, config *resourceConfig) *ResourceScope {
+	singular := singularize(resourceBaseName(path, config))
+	if !config.singular {
+		param := config.param
+		if param == "id" {
+			param = singular + "_id"
+		}
+		path += "/:" + param
+	}
+
+	namePrefix := singular + "_"
+	if g != nil {
+		namePrefix = g.namePrefix + namePrefix
 	}
+	return &ResourceScope{router: r, group: g, path: path, namePrefix: namePrefix}
+}
+
+// Nest calls fn with a group scoped to a member of the resource, so
+// resources registered on it are nested beneath the member:
+//
+//	r.Resources("/posts", &PostController{}).Nest(func(posts *Group) {
+//	    posts.Resources("/comments", &CommentController{})
+//	})
+//	// GET /posts/:post_id/comments     -> CommentController.Index
+//	// GET /posts/:post_id/comments/:id -> CommentController.Show
+//
+// The names of nested resource routes start with the singular of the
+// parent's name, as in "post_comments_index", so they don't clash with a
+// top-level resource of the same name.
+func (s *ResourceScope) Nest(fn func(g *Group)) *ResourceScope {
+	var g *Group
+	if s.group != nil {
+		g = s.group.Group(s.path)
+	} else {
+		g = s.router.Group(s.path)
+	}
+	g.namePrefix = s.namePrefix
+	fn(g)
+	return s
+}
 
-	routes := getResourceRoutes(path)
+// resourceBaseName returns the name used for a resource's route names: the
+// WithResourceName name, or else the last segment of its path
+// (e.g., "/todos" -> "todos", "/api/v1/users" -> "users")
+func resourceBaseName(path string, config *resourceConfig) string {
+	if config.name != "" {
+		return config.name
+	}
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// singularize returns a simple singular form of a plural resource name
+// (e.g., "posts" -> "post", "categories" -> "category")
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// registerResources registers the routes for a resource at fullPath, which
+// is path with any group prefix added. If requireAll is set, a controller
+// missing one of the actions causes a panic; otherwise the action is skipped.
+func (r *Router) registerResources(g *Group, path, fullPath string, controller Controller, config *resourceConfig, requireAll bool) {
+	name := resourceBaseName(path, config)
+	if g != nil {
+		name = g.namePrefix + name
+	}
+
+	// A shallow resource's members live at the last segment of its path,
+	// under any group prefix
+	memberPath := fullPath
+	if config.shallow {
+		prefix := strings.TrimSuffix(fullPath, path)
+		memberPath = prefix + "/" + path[strings.LastIndex(path, "/")+1:]
+	}
+
+	routes := getResourceRoutes(fullPath, memberPath, config.param)
+	if config.singular {
+		routes = getSingularResourceRoutes(fullPath)
+	}
+
+	for _, custom := range config.custom {
+		customPath := fullPath + "/" + custom.action
+		if custom.member && !config.singular {
+			customPath = memberPath + "/:" + config.param + "/" + custom.action
+		}
+
+		handler := getCustomActionHandler(controller, custom.action)
+		if handler == nil {
+			panic(fmt.Sprintf("controller for resource %q has no method %s(*Context) error for action %q (required for %s %s)", fullPath, actionMethodName(custom.action), custom.action, custom.method, customPath))
+		}
+
+		r.register(g, custom.method, customPath, handler, &routeConfig{name: name + "_" + custom.action, middleware: config.middleware, generated: true})
+	}
+	named := make(map[ResourceAction]bool)
 
 	for _, route := range routes {
 		if !config.shouldIncludeAction(route.action) {
@@ -177,15 +451,53 @@ func (r *Router) Resources(path string, controller Controller, opts ...ResourceO
 		handler := getControllerHandler(controller, route.action)
 		if handler == nil {
 			if requireAll {
-				panic(fmt.Sprintf("controller for resource %q must implement all ResourceController methods when using Resources() without Only() or Except() options. Missing method: %s (required for %s %s)", path, route.action, route.method, route.path))
+				method := "Resources"
+				if config.singular {
+					method = "Resource"
+				}
+				panic(fmt.Sprintf("controller for resource %q must implement all ResourceController methods when using %s() without Only() or Except() options. Missing method: %s (required for %s %s)", fullPath, method, route.action, route.method, route.path))
 			}
 			continue
 		}
 
+		// An action served by several methods, like update with PATCH and
+		// PUT, is named after the first
+		if named[route.action] {
+			r.addRoute(g, route.method, route.path, handler, &routeConfig{middleware: config.middleware})
+			continue
+		}
+		named[route.action] = true
+
 		// Generate route name like "todos_index", "todos_show", etc.
-		routeName := resourceName + "_" + string(route.action)
-		r.handle(route.method, route.path, handler, routeName, config.middleware...)
+		routeName := name + "_" + string(route.action)
+		r.register(g, route.method, route.path, handler, &routeConfig{name: routeName, middleware: config.middleware, generated: true})
+	}
+}
+
+// getCustomActionHandler looks up the controller method for a custom
+// member or collection action, returning nil if there is none
+func getCustomActionHandler(controller Controller, action string) HandlerFunc {
+	method := reflect.ValueOf(controller).MethodByName(actionMethodName(action))
+	if !method.IsValid() {
+		return nil
+	}
+	handler, ok := method.Interface().(func(*Context) error)
+	if !ok {
+		return nil
+	}
+	return handler
+}
+
+// actionMethodName converts an action name to the controller method name
+// (e.g., "publish" -> "Publish", "mark_read" -> "MarkRead")
+func actionMethodName(action string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(action, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
 	}
+	return b.String()
 }
 
 // getControllerHandler extracts the appropriate handler method from a controller
@@ -3,6 +3,7 @@ package router
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -263,3 +264,408 @@ func TestResourcesPutAlsoWorksForUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestResourcesWithResourceName(t *testing.T) {
+	r := New()
+	controller := &TestController{}
+
+	r.Resources("/user", controller, Only(IndexAction, ShowAction), WithResourceName("users"))
+	r.Group("/admin").Resources("/person", controller, Only(ShowAction), WithResourceName("people"))
+
+	namedRoutes := r.NamedRoutes()
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"users_index", "/user"},
+		{"users_show", "/user/:id"},
+		{"people_show", "/admin/person/:id"},
+	}
+
+	for _, tt := range tests {
+		route := namedRoutes[tt.name]
+		if route == nil {
+			t.Errorf("route %s not registered", tt.name)
+			continue
+		}
+		if route.Pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.name, tt.pattern, route.Pattern)
+		}
+	}
+
+	if namedRoutes["user_index"] != nil {
+		t.Error("expected the path segment not to be used for naming")
+	}
+}
+
+func TestResourcesShallow(t *testing.T) {
+	r := New()
+	controller := &TestController{}
+
+	r.Resources("/posts/:post_id/comments", controller, Shallow())
+	r.Group("/admin").Resources("/posts/:post_id/notes", controller, Shallow(), Only(IndexAction, ShowAction))
+
+	namedRoutes := r.NamedRoutes()
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"comments_index", "/posts/:post_id/comments"},
+		{"comments_new", "/posts/:post_id/comments/new"},
+		{"comments_create", "/posts/:post_id/comments"},
+		{"comments_show", "/comments/:id"},
+		{"comments_edit", "/comments/:id/edit"},
+		{"comments_update", "/comments/:id"},
+		{"comments_delete", "/comments/:id"},
+		{"notes_index", "/admin/posts/:post_id/notes"},
+		{"notes_show", "/admin/notes/:id"},
+	}
+
+	for _, tt := range tests {
+		route := namedRoutes[tt.name]
+		if route == nil {
+			t.Errorf("route %s not registered", tt.name)
+			continue
+		}
+		if route.Pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.name, tt.pattern, route.Pattern)
+		}
+	}
+
+	req := httptest.NewRequest("PUT", "/comments/5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "update" {
+		t.Errorf("expected PUT /comments/5 to update, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/posts/1/comments/5", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected nested member route to be 404, got %d", w.Code)
+	}
+}
+
+func TestResourceSingular(t *testing.T) {
+	r := New()
+	controller := &TestController{}
+
+	var params Params
+	r.Resource("/profile", controller, WithResourceMiddleware(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			params = c.Params
+			return next(c)
+		}
+	}))
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"GET", "/profile", http.StatusOK, "show"},
+		{"GET", "/profile/edit", http.StatusOK, "edit"},
+		{"GET", "/profile/new", http.StatusOK, "new"},
+		{"POST", "/profile", http.StatusCreated, "create"},
+		{"PATCH", "/profile", http.StatusOK, "update"},
+		{"PUT", "/profile", http.StatusOK, "update"},
+		{"DELETE", "/profile", http.StatusOK, "delete"},
+		{"GET", "/profile/123", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			params = nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if len(params) != 0 {
+				t.Errorf("expected no params, got %v", params)
+			}
+		})
+	}
+
+	if controller.indexCalled {
+		t.Error("expected no index action")
+	}
+	if r.NamedRoutes()["profile_index"] != nil {
+		t.Error("expected no index route")
+	}
+}
+
+func TestResourceSingularInGroup(t *testing.T) {
+	r := New()
+	controller := &TestController{}
+
+	r.Group("/account").Resource("/profile", controller, Only(ShowAction))
+
+	req := httptest.NewRequest("GET", "/account/profile", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "show" {
+		t.Errorf("expected show, got %d %q", w.Code, w.Body.String())
+	}
+	if route := r.NamedRoutes()["profile_show"]; route == nil || route.Pattern != "/account/profile" {
+		t.Errorf("expected profile_show at /account/profile, got %v", route)
+	}
+}
+
+// Controller with custom member and collection actions
+type PublishingController struct {
+	TestController
+}
+
+func (pc *PublishingController) Publish(c *Context) error {
+	return c.String(http.StatusOK, "publish "+c.Param("id"))
+}
+
+func (pc *PublishingController) Search(c *Context) error {
+	return c.String(http.StatusOK, "search")
+}
+
+func TestResourcesMemberAndCollection(t *testing.T) {
+	r := New()
+	controller := &PublishingController{}
+
+	r.Resources("/posts", controller, Member("publish", "post"), Collection("search", "GET"))
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"POST", "/posts/5/publish", http.StatusOK, "publish 5"},
+		{"GET", "/posts/search", http.StatusOK, "search"},
+		{"GET", "/posts/5", http.StatusOK, "show"},
+		{"GET", "/posts/5/publish", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	namedRoutes := r.NamedRoutes()
+	if route := namedRoutes["posts_publish"]; route == nil || route.Pattern != "/posts/:id/publish" {
+		t.Errorf("expected posts_publish at /posts/:id/publish, got %v", route)
+	}
+	if route := namedRoutes["posts_search"]; route == nil || route.Pattern != "/posts/search" {
+		t.Errorf("expected posts_search at /posts/search, got %v", route)
+	}
+}
+
+func TestResourcesMemberMissingMethodPanics(t *testing.T) {
+	r := New()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a controller without the action's method")
+		}
+	}()
+
+	r.Resources("/posts", &TestController{}, Member("archive", "POST"))
+}
+
+func TestResourcesParamName(t *testing.T) {
+	r := New()
+	controller := &PublishingController{}
+
+	var id string
+	r.Resources("/posts", controller, ParamName("post_id"), Member("publish", "POST"),
+		WithResourceMiddleware(func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				id = c.Param("post_id")
+				return next(c)
+			}
+		}))
+
+	namedRoutes := r.NamedRoutes()
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"posts_show", "/posts/:post_id"},
+		{"posts_edit", "/posts/:post_id/edit"},
+		{"posts_publish", "/posts/:post_id/publish"},
+	}
+
+	for _, tt := range tests {
+		route := namedRoutes[tt.name]
+		if route == nil {
+			t.Errorf("route %s not registered", tt.name)
+			continue
+		}
+		if route.Pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.name, tt.pattern, route.Pattern)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/posts/42", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "show" {
+		t.Errorf("expected show, got %d %q", w.Code, w.Body.String())
+	}
+	if id != "42" {
+		t.Errorf("expected post_id 42, got %q", id)
+	}
+}
+
+func TestResourcesUnknownActionPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		opt   ResourceOption
+		panic bool
+	}{
+		{"only typo", Only("indx"), true},
+		{"except typo", Except(ShowAction, "destroy"), true},
+		{"only valid", Only(IndexAction, ShowAction), false},
+		{"except valid", Except(NewAction, EditAction), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				rec := recover()
+				if tt.panic && rec == nil {
+					t.Error("expected a panic for an unknown action")
+				}
+				if !tt.panic && rec != nil {
+					t.Errorf("unexpected panic: %v", rec)
+				}
+				if msg, _ := rec.(string); tt.panic && !strings.Contains(msg, "index, new, create") {
+					t.Errorf("expected the panic to list valid actions, got %v", rec)
+				}
+			}()
+
+			New().Resources("/posts", &TestController{}, tt.opt)
+		})
+	}
+}
+
+func TestResourcesNest(t *testing.T) {
+	r := New()
+	posts := &TestController{}
+	comments := &PublishingController{}
+
+	var postID string
+	capture := WithResourceMiddleware(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			postID = c.Param("post_id")
+			return next(c)
+		}
+	})
+
+	r.Resources("/posts", posts).Nest(func(g *Group) {
+		g.Resources("/comments", comments, capture)
+	})
+	r.Group("/api").Resources("/categories", posts, Only(ShowAction)).Nest(func(g *Group) {
+		g.Resources("/items", comments, Only(IndexAction))
+	})
+
+	namedRoutes := r.NamedRoutes()
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"posts_show", "/posts/:id"},
+		{"post_comments_index", "/posts/:post_id/comments"},
+		{"post_comments_show", "/posts/:post_id/comments/:id"},
+		{"category_items_index", "/api/categories/:category_id/items"},
+	}
+
+	for _, tt := range tests {
+		route := namedRoutes[tt.name]
+		if route == nil {
+			t.Errorf("route %s not registered", tt.name)
+			continue
+		}
+		if route.Pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.name, tt.pattern, route.Pattern)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/posts/7/comments/3", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "show" {
+		t.Errorf("expected show, got %d %q", w.Code, w.Body.String())
+	}
+	if postID != "7" {
+		t.Errorf("expected post_id 7, got %q", postID)
+	}
+	if !comments.showCalled || posts.showCalled {
+		t.Error("expected the nested controller to handle the request")
+	}
+}
+
+func TestResourcesInSeveralGroups(t *testing.T) {
+	r := New()
+	r.Group("/api/v1").Resources("/users", &TestController{})
+	r.Group("/api/v2").Resources("/users", &TestController{})
+
+	for _, path := range []string{"/api/v1/users", "/api/v2/users/1"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: expected 200, got %d", path, w.Code)
+		}
+	}
+
+	// Like other generated names, the latest resource takes the name
+	if route := r.NamedRoutes()["users_index"]; route == nil || route.Pattern != "/api/v2/users" {
+		t.Errorf("expected users_index to name the v2 route, got %+v", route)
+	}
+}
+
+func TestResourcesNestAndTopLevel(t *testing.T) {
+	r := New()
+	r.Resources("/posts", &TestController{}).Nest(func(g *Group) {
+		g.Resources("/comments", &TestController{}, Only(IndexAction)).Nest(func(g *Group) {
+			g.Resources("/likes", &TestController{}, Only(IndexAction))
+		})
+	})
+	r.Resources("/comments", &TestController{})
+
+	namedRoutes := r.NamedRoutes()
+	tests := map[string]string{
+		"post_comments_index":      "/posts/:post_id/comments",
+		"post_comment_likes_index": "/posts/:post_id/comments/:comment_id/likes",
+		"comments_index":           "/comments",
+		"comments_show":            "/comments/:id",
+	}
+	for name, pattern := range tests {
+		if route := namedRoutes[name]; route == nil || route.Pattern != pattern {
+			t.Errorf("expected %s to be %s, got %+v", name, pattern, route)
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package routehelper
 
 import (
+	"bytes"
 	"fmt"
 	"go/format"
+	"go/token"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
+	"unicode"
 )
 
 // RouteInfo holds metadata about a route for code generation
@@ -19,20 +24,57 @@ type RouteInfo struct {
 
 // RouteParam represents a parameter in a route
 type RouteParam struct {
-	Name string
-	Type string // "string", "int", etc.
+	Name    string
+	Type    string // "string", "int", etc.
+	Segment string // the pattern segment the value replaces, e.g. ":id(int)"
+
+	// Optional params (:slug?) are left out of the path when empty
+	Optional bool
+
+	// Wildcard params (*filepath) take the rest of the path, slashes included
+	Wildcard bool
 }
 
 // Generator generates type-safe route helper functions
 type Generator struct {
 	routes []RouteInfo
+
+	// Emit methods on a Routes struct instead of package-level functions
+	asStruct bool
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithRoutesStruct generates the helpers as methods on a Routes struct
+// instead of package-level functions:
+//
+//	type Routes struct {
+//	    Host string
+//	}
+//
+//	func (r Routes) UserShowPath(id string, query ...url.Values) string
+//	func (r Routes) UserShowURL(id string, query ...url.Values) string
+//
+// The URL methods prepend the struct's Host rather than taking it as an
+// argument, so a Routes value can be configured once and injected, or
+// replaced by an interface in tests. The map of route names to patterns,
+// called Routes otherwise, is generated as RoutePatterns.
+func WithRoutesStruct() Option {
+	return func(g *Generator) {
+		g.asStruct = true
+	}
 }
 
 // New creates a new route helper generator instance
-func New() *Generator {
-	return &Generator{
+func New(opts ...Option) *Generator {
+	g := &Generator{
 		routes: make([]RouteInfo, 0),
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 // AddRoute registers a route for code generation
@@ -52,12 +94,41 @@ func extractParameters(pattern string) []RouteParam {
 	params := make([]RouteParam, 0)
 
 	for _, part := range parts {
-		if strings.HasPrefix(part, ":") {
-			paramName := strings.TrimPrefix(part, ":")
-			// Default to string, could be enhanced with type hints
+		// {id} and {*path} are the same as :id and *path
+		spec := part
+		if len(part) > 2 && part[0] == '{' && part[len(part)-1] == '}' {
+			if spec = part[1 : len(part)-1]; spec[0] != '*' {
+				spec = ":" + spec
+			}
+		}
+
+		if strings.HasPrefix(spec, "*") {
 			params = append(params, RouteParam{
-				Name: paramName,
-				Type: "string",
+				Name:     strings.TrimPrefix(spec, "*"),
+				Type:     "string",
+				Segment:  part,
+				Wildcard: true,
+			})
+			continue
+		}
+		if strings.HasPrefix(spec, ":") {
+			paramName := strings.TrimPrefix(spec, ":")
+			optional := strings.HasSuffix(paramName, "?")
+			paramName = strings.TrimSuffix(paramName, "?")
+			// Drop a constraint such as :id(int); int params take an int,
+			// unless they're optional and need "" to leave them out
+			paramType := "string"
+			if i := strings.IndexByte(paramName, '('); i >= 0 {
+				if paramName[i:] == "(int)" && !optional {
+					paramType = "int"
+				}
+				paramName = paramName[:i]
+			}
+			params = append(params, RouteParam{
+				Name:     paramName,
+				Type:     paramType,
+				Segment:  part,
+				Optional: optional,
 			})
 		}
 	}
@@ -65,7 +136,8 @@ func extractParameters(pattern string) []RouteParam {
 	return params
 }
 
-// Generate creates the Go source file with route helpers
+// Generate creates the Go source file with route helpers.
+// If there are no routes, an existing file is removed instead.
 func (g *Generator) Generate(packageName, outputFile string) error {
 	// If no routes exist, remove the generated file if it exists
 	if len(g.routes) == 0 {
@@ -77,19 +149,55 @@ func (g *Generator) Generate(packageName, outputFile string) error {
 		return nil
 	}
 
-	// Check if any route has parameters
-	hasParams := false
-	for _, route := range g.routes {
+	var buf bytes.Buffer
+	if err := g.GenerateTo(&buf, packageName); err != nil {
+		return err
+	}
+
+	// Create parent directories if they don't exist
+	dir := filepath.Dir(outputFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	// Write to file
+	return os.WriteFile(outputFile, buf.Bytes(), 0644)
+}
+
+// GenerateTo writes the formatted Go source for the route helpers to w.
+// Unlike Generate, it writes a file with just the package clause when
+// there are no routes.
+func (g *Generator) GenerateTo(w io.Writer, packageName string) error {
+	// Sort by name so the output doesn't depend on the order routes
+	// were added in
+	routes := slices.Clone(g.routes)
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if err := checkIdentifiers(routes); err != nil {
+		return err
+	}
+
+	// Check if any route has parameters, and if any are ints
+	hasParams, hasInts := false, false
+	for _, route := range routes {
 		if len(route.Parameters) > 0 {
 			hasParams = true
-			break
+		}
+		for _, param := range route.Parameters {
+			if param.Type == "int" {
+				hasInts = true
+			}
 		}
 	}
 
 	tmpl := template.Must(template.New("routes").Funcs(template.FuncMap{
 		"camelCase":  toCamelCase,
+		"ident":      toIdentifier,
 		"paramList":  makeParamList,
 		"paramNames": makeParamNames,
+		"paramValue": makeParamValue,
 		"hasParams":  func() bool { return hasParams },
 	}).Parse(routeTemplate))
 
@@ -97,10 +205,14 @@ func (g *Generator) Generate(packageName, outputFile string) error {
 		Package   string
 		Routes    []RouteInfo
 		HasParams bool
+		HasInts   bool
+		Struct    bool
 	}{
 		Package:   packageName,
-		Routes:    g.routes,
+		Routes:    routes,
 		HasParams: hasParams,
+		HasInts:   hasInts,
+		Struct:    g.asStruct,
 	}
 
 	var builder strings.Builder
@@ -114,14 +226,8 @@ func (g *Generator) Generate(packageName, outputFile string) error {
 		return fmt.Errorf("formatting failed: %w", err)
 	}
 
-	// Create parent directories if they don't exist
-	dir := filepath.Dir(outputFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	// Write to file
-	return os.WriteFile(outputFile, formatted, 0644)
+	_, err = w.Write(formatted)
+	return err
 }
 
 // Helper functions for template
@@ -137,6 +243,65 @@ func toCamelCase(s string) string {
 	return strings.Join(parts, "")
 }
 
+// checkIdentifiers returns an error if two routes would generate helpers
+// with the same name, as "user-show" and "user_show" do, or two parameters
+// of a route would become the same argument, as :user-id and :user_id do.
+// The generated file wouldn't compile.
+func checkIdentifiers(routes []RouteInfo) error {
+	helpers := make(map[string]string, len(routes))
+	for _, route := range routes {
+		helper := toCamelCase(route.Name)
+		if other, ok := helpers[helper]; ok {
+			return fmt.Errorf("routes %q and %q both generate the helper %sPath", other, route.Name, helper)
+		}
+		helpers[helper] = route.Name
+
+		args := make(map[string]string, len(route.Parameters))
+		for _, param := range route.Parameters {
+			arg := toIdentifier(param.Name)
+			if other, ok := args[arg]; ok {
+				return fmt.Errorf("parameters %q and %q of route %q both become the argument %s", other, param.Name, route.Name, arg)
+			}
+			args[arg] = param.Name
+		}
+	}
+	return nil
+}
+
+// reservedIdentifiers are names used by the generated code itself,
+// which a parameter must not shadow
+var reservedIdentifiers = map[string]bool{
+	"host":    true,
+	"path":    true,
+	"query":   true,
+	"r":       true, // receiver of the Routes methods
+	"strconv": true,
+	"strings": true,
+	"url":     true,
+}
+
+// toIdentifier converts a route parameter name into a valid Go identifier
+// for use in generated function signatures. Invalid characters (such as the
+// hyphen in :user-id) become underscores, and names that are Go keywords or
+// clash with the generated code get a trailing underscore.
+func toIdentifier(name string) string {
+	ident := []rune(name)
+	for i, r := range ident {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			ident[i] = '_'
+		}
+	}
+
+	s := string(ident)
+	if s == "" || unicode.IsDigit(ident[0]) {
+		s = "_" + s
+	}
+	if token.IsKeyword(s) || reservedIdentifiers[s] {
+		s += "_"
+	}
+	return s
+}
+
 func makeParamList(params []RouteParam) string {
 	if len(params) == 0 {
 		return ""
@@ -144,7 +309,7 @@ func makeParamList(params []RouteParam) string {
 
 	parts := make([]string, len(params))
 	for i, p := range params {
-		parts[i] = fmt.Sprintf("%s %s", p.Name, p.Type)
+		parts[i] = fmt.Sprintf("%s %s", toIdentifier(p.Name), p.Type)
 	}
 	return strings.Join(parts, ", ")
 }
@@ -156,34 +321,81 @@ func makeParamNames(params []RouteParam) string {
 
 	names := make([]string, len(params))
 	for i, p := range params {
-		names[i] = p.Name
+		names[i] = toIdentifier(p.Name)
 	}
 	return strings.Join(names, ", ")
 }
 
+// makeParamValue returns the expression for a parameter's value as a
+// path segment, escaped so values such as "a/b" stay in their segment.
+// A wildcard's value is escaped as a path instead, keeping its slashes.
+func makeParamValue(p RouteParam) string {
+	if p.Type == "int" {
+		return "strconv.Itoa(" + toIdentifier(p.Name) + ")"
+	}
+	if p.Wildcard {
+		// Escape the value as a path, so its slashes separate segments
+		return "(&url.URL{Path: strings.TrimPrefix(" + toIdentifier(p.Name) + `, "/")}).EscapedPath()`
+	}
+	return "url.PathEscape(" + toIdentifier(p.Name) + ")"
+}
+
 // Template for generated code
 const routeTemplate = `// Code generated by router path helper generator. DO NOT EDIT.
 package {{.Package}}
+{{- if .Routes}}
 
 import (
 	"net/url"
+{{- if .HasInts}}
+	"strconv"
+{{- end}}
 {{- if .HasParams}}
 	"strings"
 {{- end}}
 )
+{{- end}}
+
+{{- if .Struct}}
+
+// Routes generates paths and URLs for the named routes.
+// The URL methods prefix paths with Host.
+type Routes struct {
+	Host string
+}
+
+// RoutePatterns maps each route name to its pattern
+var RoutePatterns = map[string]string{
+{{- template "patterns" .Routes}}
+}
+{{- else if .Routes}}
+
+// Routes maps each route name to its pattern
+var Routes = map[string]string{
+{{- template "patterns" .Routes}}
+}
+{{- end}}
 
 {{range .Routes}}
 // {{camelCase .Name}}Path generates a path for the {{.Name}} route
 // Route: {{.Method}} {{.Pattern}}
 // Optional query parameters can be passed as the last argument
-func {{camelCase .Name}}Path({{paramList .Parameters}}{{if .Parameters}}, {{end}}query ...url.Values) string {
+func {{if $.Struct}}(r Routes) {{end}}{{camelCase .Name}}Path({{paramList .Parameters}}{{if .Parameters}}, {{end}}query ...url.Values) string {
 {{- if .Parameters}}
-	path := "{{.Pattern}}"
+	path := {{printf "%q" .Pattern}}
 	{{range .Parameters -}}
-	path = strings.Replace(path, ":{{.Name}}", {{.Name}}, 1)
+	{{if .Optional -}}
+	if {{ident .Name}} == "" {
+		path = strings.Replace(path, {{printf "%q" (print "/" .Segment)}}, "", 1)
+	} else {
+		path = strings.Replace(path, {{printf "%q" .Segment}}, {{paramValue .}}, 1)
+	}
+	{{else -}}
+	path = strings.Replace(path, {{printf "%q" .Segment}}, {{paramValue .}}, 1)
+	{{end -}}
 	{{end -}}
 {{- else}}
-	path := "{{.Pattern}}"
+	path := {{printf "%q" .Pattern}}
 {{- end}}
 	if len(query) > 0 && len(query[0]) > 0 {
 		path += "?" + query[0].Encode()
@@ -193,8 +405,20 @@ func {{camelCase .Name}}Path({{paramList .Parameters}}{{if .Parameters}}, {{end}
 
 // {{camelCase .Name}}URL generates a full URL for the {{.Name}} route
 // Optional query parameters can be passed as the last argument
+{{- if $.Struct}}
+func (r Routes) {{camelCase .Name}}URL({{paramList .Parameters}}{{if .Parameters}}, {{end}}query ...url.Values) string {
+	return r.Host + r.{{camelCase .Name}}Path({{paramNames .Parameters}}{{if .Parameters}}, {{end}}query...)
+}
+{{- else}}
 func {{camelCase .Name}}URL(host string{{if .Parameters}}, {{paramList .Parameters}}{{end}}, query ...url.Values) string {
 	return host + {{camelCase .Name}}Path({{paramNames .Parameters}}{{if .Parameters}}, {{end}}query...)
 }
+{{- end}}
 {{end}}
+
+{{- define "patterns"}}
+{{- range .}}
+	{{printf "%q" .Name}}: {{printf "%q" .Pattern}},
+{{- end}}
+{{- end}}
 `
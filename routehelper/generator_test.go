@@ -1,6 +1,7 @@
 package routehelper
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,6 +33,14 @@ func TestExtractParameters(t *testing.T) {
 			pattern:  "/:a/:b/:c",
 			expected: []RouteParam{{Name: "a", Type: "string"}, {Name: "b", Type: "string"}, {Name: "c", Type: "string"}},
 		},
+		{
+			pattern:  "/users/{id}",
+			expected: []RouteParam{{Name: "id", Type: "string"}},
+		},
+		{
+			pattern:  "/users/{user_id(int)}/files/{*path}",
+			expected: []RouteParam{{Name: "user_id", Type: "int"}, {Name: "path", Type: "string"}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -76,6 +85,31 @@ func TestToCamelCase(t *testing.T) {
 	}
 }
 
+func TestToIdentifier(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"id", "id"},
+		{"user_id", "user_id"},
+		{"user-id", "user_id"},
+		{"file.name", "file_name"},
+		{"2fa", "_2fa"},
+		{"type", "type_"},
+		{"path", "path_"},
+		{"query", "query_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := toIdentifier(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestMakeParamList(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -280,3 +314,358 @@ func TestGeneratorGenerateComplexRoutes(t *testing.T) {
 		t.Errorf("generated code has incorrect signature for NestedResourcePath")
 	}
 }
+
+func TestGeneratorGenerateSanitizesParamNames(t *testing.T) {
+	rh := New()
+	rh.AddRoute("user_show", "/users/:user-id", "GET")
+	rh.AddRoute("file_show", "/files/:path/:type", "GET")
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	// Unsanitized names produce code that fails to format
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	contentStr := string(content)
+
+	expected := []string{
+		"func UserShowPath(user_id string, query ...url.Values) string",
+		`path = strings.Replace(path, ":user-id", url.PathEscape(user_id), 1)`,
+		"func UserShowURL(host string, user_id string, query ...url.Values) string",
+		"func FileShowPath(path_ string, type_ string, query ...url.Values) string",
+		`path = strings.Replace(path, ":path", url.PathEscape(path_), 1)`,
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(contentStr, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+}
+
+func TestGeneratorGenerateConstrainedParams(t *testing.T) {
+	rh := New()
+	rh.AddRoute("user_show", "/users/:id(int)/posts/:slug", "GET")
+
+	if params := rh.routes[0].Parameters; len(params) != 2 || params[0].Name != "id" {
+		t.Fatalf("expected constraint to be stripped from the parameter name, got %+v", params)
+	}
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	contentStr := string(content)
+
+	expected := []string{
+		"func UserShowPath(id int, slug string, query ...url.Values) string",
+		`path = strings.Replace(path, ":id(int)", strconv.Itoa(id), 1)`,
+		`"strconv"`,
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(contentStr, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+}
+
+func TestGeneratorGenerateRoutesStruct(t *testing.T) {
+	rh := New(WithRoutesStruct())
+	rh.AddRoute("home", "/", "GET")
+	rh.AddRoute("user_show", "/users/:id", "GET")
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	contentStr := string(content)
+
+	expected := []string{
+		"type Routes struct {\n\tHost string\n}",
+		"func (r Routes) HomePath(query ...url.Values) string",
+		"func (r Routes) HomeURL(query ...url.Values) string",
+		"func (r Routes) UserShowPath(id string, query ...url.Values) string",
+		"func (r Routes) UserShowURL(id string, query ...url.Values) string",
+		"return r.Host + r.UserShowPath(id, query...)",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(contentStr, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+
+	if strings.Contains(contentStr, "func UserShowPath(") {
+		t.Error("expected no package-level functions in struct mode")
+	}
+}
+
+func TestGeneratorGenerateOptionalParams(t *testing.T) {
+	rh := New()
+	rh.AddRoute("post_show", "/posts/:id/:slug?", "GET")
+
+	if params := rh.routes[0].Parameters; len(params) != 2 || params[1].Name != "slug" || !params[1].Optional {
+		t.Fatalf("expected an optional slug parameter, got %+v", params)
+	}
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	contentStr := string(content)
+
+	expected := []string{
+		"func PostShowPath(id string, slug string, query ...url.Values) string",
+		`if slug == "" {`,
+		`path = strings.Replace(path, "/:slug?", "", 1)`,
+		`path = strings.Replace(path, ":slug?", url.PathEscape(slug), 1)`,
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(contentStr, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+}
+
+func TestGeneratorGenerateRegexParams(t *testing.T) {
+	rh := New()
+	rh.AddRoute("image_show", `/images/:name([a-z]+\.png)`, "GET")
+
+	if params := rh.routes[0].Parameters; len(params) != 1 || params[0].Name != "name" {
+		t.Fatalf("expected the pattern to be stripped from the parameter name, got %+v", params)
+	}
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	// The backslash must be escaped for the generated code to parse
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	if want := `path = strings.Replace(path, ":name([a-z]+\\.png)", url.PathEscape(name), 1)`; !strings.Contains(string(content), want) {
+		t.Errorf("generated code missing %s", want)
+	}
+}
+
+func TestGeneratorGenerateEscapesParams(t *testing.T) {
+	rh := New()
+	rh.AddRoute("user_show", "/users/:id", "GET")
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "routes.go")
+
+	if err := rh.Generate("routes", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	if want := `path = strings.Replace(path, ":id", url.PathEscape(id), 1)`; !strings.Contains(string(content), want) {
+		t.Errorf("generated code missing %s", want)
+	}
+}
+
+func TestGeneratorGenerateTo(t *testing.T) {
+	rh := New()
+	rh.AddRoute("user_show", "/users/:id", "GET")
+
+	var buf bytes.Buffer
+	if err := rh.GenerateTo(&buf, "links"); err != nil {
+		t.Fatalf("GenerateTo failed: %v", err)
+	}
+
+	content := buf.String()
+	expected := []string{
+		"package links",
+		"func UserShowPath(id string, query ...url.Values) string",
+		"func UserShowURL(host string, id string, query ...url.Values) string",
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+
+	// The file variant writes the same source
+	outputFile := filepath.Join(t.TempDir(), "routes.go")
+	if err := rh.Generate("links", outputFile); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	written, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+	if string(written) != content {
+		t.Error("expected Generate to write the same source as GenerateTo")
+	}
+}
+
+func TestGeneratorGenerateIdentifierCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes [][2]string // name, pattern
+		want   []string
+	}{
+		{"route names", [][2]string{{"user-show", "/users/:id"}, {"user_show", "/people/:id"}}, []string{`"user-show"`, `"user_show"`, "UserShowPath"}},
+		{"param names", [][2]string{{"user_show", "/users/:user-id/:user_id"}}, []string{`"user-id"`, `"user_id"`, `"user_show"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rh := New()
+			for _, route := range tt.routes {
+				rh.AddRoute(route[0], route[1], "GET")
+			}
+
+			var buf bytes.Buffer
+			err := rh.GenerateTo(&buf, "routes")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected the error to mention %s, got %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratorGenerateToNoRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New().GenerateTo(&buf, "routes"); err != nil {
+		t.Fatalf("GenerateTo failed: %v", err)
+	}
+
+	content := buf.String()
+	if !strings.Contains(content, "package routes") {
+		t.Errorf("expected a package clause, got %q", content)
+	}
+	if strings.Contains(content, "import") {
+		t.Errorf("expected no imports without routes, got %q", content)
+	}
+}
+
+func TestGeneratorGenerateSortsRoutes(t *testing.T) {
+	var outputs []string
+	for _, order := range [][]string{{"b_show", "a_show", "c_show"}, {"c_show", "b_show", "a_show"}} {
+		rh := New()
+		for _, name := range order {
+			rh.AddRoute(name, "/"+name, "GET")
+		}
+
+		var buf bytes.Buffer
+		if err := rh.GenerateTo(&buf, "routes"); err != nil {
+			t.Fatalf("GenerateTo failed: %v", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Error("expected the same output whatever order routes were added in")
+	}
+	if a, c := strings.Index(outputs[0], "func AShowPath"), strings.Index(outputs[0], "func CShowPath"); a > c {
+		t.Error("expected helpers sorted by name")
+	}
+}
+
+func TestGeneratorGenerateRoutesMap(t *testing.T) {
+	rh := New()
+	rh.AddRoute("user_show", "/users/:id", "GET")
+	rh.AddRoute("users_index", "/users", "GET")
+	rh.AddRoute("image_show", `/images/:name([a-z]+\.png)`, "GET")
+
+	var buf bytes.Buffer
+	if err := rh.GenerateTo(&buf, "routes"); err != nil {
+		t.Fatalf("GenerateTo failed: %v", err)
+	}
+
+	content := buf.String()
+	expected := []string{
+		"var Routes = map[string]string{",
+		`"image_show":  "/images/:name([a-z]+\\.png)",`,
+		`"user_show":   "/users/:id",`,
+		`"users_index": "/users",`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+
+	// The Routes struct takes the name in struct mode
+	rh.asStruct = true
+	buf.Reset()
+	if err := rh.GenerateTo(&buf, "routes"); err != nil {
+		t.Fatalf("GenerateTo failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "var RoutePatterns = map[string]string{") {
+		t.Error("expected the map to be named RoutePatterns in struct mode")
+	}
+}
+
+func TestGeneratorGenerateWildcardParam(t *testing.T) {
+	rh := New()
+	rh.AddRoute("files_show", "/files/*filepath", "GET")
+
+	var buf bytes.Buffer
+	if err := rh.GenerateTo(&buf, "routes"); err != nil {
+		t.Fatalf("GenerateTo failed: %v", err)
+	}
+
+	content := buf.String()
+	expected := []string{
+		"func FilesShowPath(filepath string, query ...url.Values) string",
+		`path = strings.Replace(path, "*filepath", (&url.URL{Path: strings.TrimPrefix(filepath, "/")}).EscapedPath(), 1)`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated code missing %s", want)
+		}
+	}
+	if strings.Contains(content, "url.PathEscape(filepath)") {
+		t.Error("expected the wildcard value not to be escaped as a single segment")
+	}
+}
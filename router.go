@@ -48,7 +48,8 @@
 // Error Handling:
 //
 // Handlers return errors, which are processed by the ErrorHandler.
-// The default ErrorHandler sends a JSON error response:
+// The default ErrorHandler sends a JSON error response, using the status
+// code of a StatusError or 500 for any other error:
 //
 //	r.Get("/users/:id", func(c *Context) error {
 //	    user, err := findUser(c.Param("id"))
@@ -105,9 +106,23 @@
 package router
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/douglasgreyling/router/internal/naming"
 	"github.com/douglasgreyling/router/internal/tree"
@@ -149,30 +164,179 @@ type Router struct {
 	// Global middleware applied to all routes
 	middleware []MiddlewareFunc
 
-	// NotFound handler
+	// Dispatchers for routes registered with WithHost, keyed by routeKey
+	hosts map[string]*hostSwitch
+
+	// Groups created on the router, keyed by prefix without a trailing
+	// slash, for their NotFound handlers
+	groups map[string][]*Group
+
+	// routesMu serializes route registration, which is rejected once
+	// frozen is set by Freeze
+	routesMu sync.Mutex
+	frozen   bool
+
+	// Composed handler and middleware chains of matched routes, keyed by
+	// chainKey. Entries built before chainVersion last changed (on Use or
+	// registration) are stale and rebuilt on the next request.
+	chains       sync.Map
+	chainVersion atomic.Uint64
+
+	// TrustedProxies parsed on first use by trustedProxies
+	trustedOnce sync.Once
+	trusted     []*net.IPNet
+
+	// HTTP server started by Serve, created on first use by Server
+	server   *http.Server
+	serverMu sync.Mutex
+
+	// NotFound handles requests that match no route. Like the route
+	// handlers, it runs inside the global middleware.
 	NotFound HandlerFunc
 
-	// MethodNotAllowed handler
+	// MethodNotAllowed handles requests whose path matches a route
+	// registered for other methods. It runs inside the global middleware.
 	MethodNotAllowed HandlerFunc
 
 	// ErrorHandler handles errors returned from handlers
 	ErrorHandler func(*Context, error)
+
+	// Renderer renders templates for Context.Render and Context.RenderStream
+	Renderer Renderer
+
+	// JSONEncoder creates the encoder used by Context.JSON and
+	// Context.JSONPretty, to change encoding settings for every response:
+	//
+	//	r.JSONEncoder = func(w io.Writer) *json.Encoder {
+	//	    enc := json.NewEncoder(w)
+	//	    enc.SetEscapeHTML(false)
+	//	    return enc
+	//	}
+	//
+	// Defaults to json.NewEncoder.
+	JSONEncoder func(io.Writer) *json.Encoder
+
+	// CleanWildcardPaths normalizes the value captured by a *wildcard
+	// segment before it reaches the handler: duplicate slashes, "." and ".."
+	// elements, and leading or trailing slashes are removed, as by
+	// Context.WildcardPath. Off by default so handlers see the raw capture.
+	CleanWildcardPaths bool
+
+	// ExpectContinueHandler decides whether to accept a request sent with
+	// "Expect: 100-continue" before its body is read. It runs after routing
+	// (so route params are available) and before any middleware. Returning
+	// false rejects the request with the returned status (417 Expectation
+	// Failed if 0), which is passed to the ErrorHandler as a StatusError.
+	//
+	// net/http only sends "100 Continue" once the body is first read, so a
+	// rejected client never streams its body:
+	//
+	//	r.ExpectContinueHandler = func(c *Context) (bool, int) {
+	//	    if c.Request.ContentLength > maxUpload {
+	//	        return false, http.StatusRequestEntityTooLarge
+	//	    }
+	//	    return true, 0
+	//	}
+	ExpectContinueHandler func(*Context) (bool, int)
+
+	// HeadAsGet keeps HEAD routes out of the named route registry so they
+	// share the name and generated helper of the matching GET route.
+	// HEAD routes given an explicit name with WithName are still registered.
+	HeadAsGet bool
+
+	// MaxHeaderCount limits the number of header lines in a request.
+	// Requests with more are rejected with 431 Request Header Fields Too
+	// Large before routing, through the ErrorHandler. Zero means no limit.
+	MaxHeaderCount int
+
+	// MaxHeaderBytes limits the total size of a request's header names and
+	// values. Requests over the limit are rejected like MaxHeaderCount.
+	// Serve also sets it on the http.Server, which stops reading oversized
+	// headers early and answers with its own plain 431. Zero means no limit
+	// here and the net/http default in Serve.
+	MaxHeaderBytes int
+
+	// HandleOPTIONS answers OPTIONS requests for paths without an OPTIONS
+	// route with 204 No Content and an Allow header listing the methods
+	// registered for the path. Explicit OPTIONS routes take precedence.
+	// The response goes through the global middleware, so it can be
+	// used with CORS to answer preflight requests.
+	HandleOPTIONS bool
+
+	// RedirectTrailingSlash redirects requests whose trailing slash doesn't
+	// match the registered route, so /users/ redirects to /users and /users
+	// to a route registered as /users/. Without it both forms are served.
+	// GET and HEAD requests get 301 Moved Permanently, other methods 308
+	// Permanent Redirect so the method and body are kept. Wildcard routes
+	// are exempt.
+	RedirectTrailingSlash bool
+
+	// MaxMultipartMemory is the number of bytes of a multipart form kept in
+	// memory by Context.FormValue and Context.FormFile; the rest of the
+	// uploaded files are stored in temporary files. Defaults to 32 MB.
+	MaxMultipartMemory int64
+
+	// MaxBodyBytes limits the size of request bodies. Reads past the limit,
+	// such as in Context.Body or Context.BindJSON, fail with an
+	// *http.MaxBytesError, which the default ErrorHandler reports as 413
+	// Request Entity Too Large. Zero means no limit.
+	MaxBodyBytes int64
+
+	// TrustedProxies lists the IP addresses and CIDR ranges, such as
+	// "10.0.0.0/8", of the proxies in front of the router. When set,
+	// Context.ClientIP only believes X-Forwarded-For and X-Real-IP on
+	// requests from a trusted proxy, and takes the client from the right-most
+	// X-Forwarded-For entry that isn't a trusted proxy, as the entries
+	// further left can be forged by the client. Invalid entries are ignored.
+	// When empty, the headers are always believed. The list is parsed the
+	// first time it is needed, so set it before serving requests.
+	TrustedProxies []string
+
+	// NameGenerator names routes registered without WithName. It receives
+	// the full path and method; returning "" leaves the route unnamed.
+	// Defaults to Rails-style names such as users_index and users_show.
+	// Set it before registering routes.
+	NameGenerator func(path, method string) string
+
+	// AllowOverride lets a route be registered again for the same method
+	// and path, replacing the earlier handler. By default registering a
+	// duplicate route panics, since it usually means one of them is dead.
+	AllowOverride bool
+
+	// BufferResponses holds each response in memory until the handler and
+	// middleware return. If they return an error, whatever they wrote is
+	// discarded, headers included, so the ErrorHandler can send a clean
+	// error response instead of one appended to partial content. Otherwise
+	// the buffered response is sent.
+	//
+	// Flushing the response, as Context.Stream does, sends what has been
+	// buffered and lets later writes through, so streaming handlers still
+	// stream; an error after the first flush can't replace the response.
+	BufferResponses bool
+
+	// PreDispatch hooks run in order on every request before it is
+	// routed, so they can change what it matches, such as its method or
+	// path. They run after the MaxHeaderCount and MaxHeaderBytes checks.
+	// Middleware can't do this, as it only runs once a route has matched.
+	//
+	//	r.PreDispatch = append(r.PreDispatch, router.MethodOverride())
+	PreDispatch []func(*http.Request)
 }
 
+// defaultMaxMultipartMemory is the default for Router.MaxMultipartMemory
+const defaultMaxMultipartMemory = 32 << 20 // 32 MB
+
 // New creates a new Router instance
 func New() *Router {
 	return &Router{
 		tree:  tree.New(),
 		names: naming.NewRegistry(),
+		hosts: make(map[string]*hostSwitch),
 		NotFound: func(c *Context) error {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "Not Found",
-			})
+			return NotFound("Not Found")
 		},
 		MethodNotAllowed: func(c *Context) error {
-			return c.JSON(http.StatusMethodNotAllowed, map[string]string{
-				"error": "Method Not Allowed",
-			})
+			return MethodNotAllowed("Method Not Allowed")
 		},
 		ErrorHandler: func(c *Context, err error) {
 			// Can't modify response if headers already sent
@@ -181,51 +345,219 @@ func New() *Router {
 				fmt.Fprintf(os.Stderr, "Error after headers sent: %v\n", err)
 				return
 			}
-			c.JSON(http.StatusInternalServerError, map[string]string{
+			c.JSON(statusCode(err), map[string]string{
 				"error": err.Error(),
 			})
 		},
+		MaxMultipartMemory: defaultMaxMultipartMemory,
 	}
 }
 
 // Use adds global middleware to the router
 func (r *Router) Use(middleware ...MiddlewareFunc) {
 	r.middleware = append(r.middleware, middleware...)
+	r.chainVersion.Add(1)
+}
+
+// Freeze marks the router's routes as final. Registering a route after
+// Freeze panics, and route lookups no longer take a lock.
+//
+// Routes and groups may be registered from several goroutines at once, for
+// example by plugins setting themselves up in parallel; registration is
+// serialized by a lock. Until Freeze is called, each lookup also takes a
+// read lock on the route tree. Call Freeze once every route is registered,
+// before serving, to drop that cost:
+//
+//	r := router.New()
+//	registerRoutes(r)
+//	r.Freeze()
+//	r.Serve()
+//
+// Registering routes while requests are being served is not supported,
+// frozen or not: the router's settings and middleware are read without
+// locking.
+func (r *Router) Freeze() {
+	r.routesMu.Lock()
+	defer r.routesMu.Unlock()
+
+	r.frozen = true
+	r.tree.Freeze()
 }
 
 // handle registers a new route with the given method and path.
-// This is an internal method called by HTTP method helpers (Get, Post, etc.).
-// A route name is automatically generated if not provided.
+// This is an internal method used to register routes without options.
+// A route name is automatically generated if not provided. It panics on
+// the errors tryRegister returns, which the Try variants such as TryGet
+// return instead.
 //
 // Panics if:
 //   - path does not begin with '/'
 //   - path contains duplicate parameter names (e.g., /users/:id/posts/:id)
 func (r *Router) handle(method, path string, handler HandlerFunc, name string, middleware ...MiddlewareFunc) {
-	// Convert middleware to interface{} slice for tree package
-	mw := make([]interface{}, len(middleware))
-	for i, m := range middleware {
-		mw[i] = m
-	}
+	r.register(nil, method, path, handler, &routeConfig{name: name, middleware: middleware})
+}
 
-	// Add route to tree
-	if err := r.tree.AddRoute(method, path, handler, mw); err != nil {
+// register adds a route to the tree and the named route registry,
+// panicking if it can't (see tryRegister).
+func (r *Router) register(g *Group, method, path string, handler HandlerFunc, cfg *routeConfig) {
+	if err := r.tryRegister(g, method, path, handler, cfg); err != nil {
 		panic(err.Error())
 	}
+}
+
+// tryRegister adds a route to the tree and the named route registry.
+// g is the group the route was registered on, or nil. The group itself is
+// stored in the route's middleware list so that its middleware is resolved
+// per request (see resolveMiddleware).
+//
+// The route's names are checked before it is added, so when tryRegister
+// returns an error the route hasn't been registered.
+func (r *Router) tryRegister(g *Group, method, path string, handler HandlerFunc, cfg *routeConfig) error {
+	r.routesMu.Lock()
+	defer r.routesMu.Unlock()
+
+	// Name and generate helpers from the :param form the tree uses
+	path = tree.NormalizePath(path)
+
+	version := ""
+	if g != nil {
+		version = g.version
+	}
+
+	name := cfg.name
+	explicit := name != "" && !cfg.generated
 
 	// Auto-generate route name if not provided
 	if name == "" {
-		name = naming.GenerateName(path, method)
+		// HEAD mirrors GET, so don't give it a helper of its own
+		if method == "HEAD" && r.HeadAsGet {
+			return r.addRouteLocked(g, method, path, handler, cfg)
+		}
+		if r.NameGenerator != nil {
+			name = r.NameGenerator(path, method)
+		} else {
+			name = naming.GenerateName(path, method)
+		}
+	} else if version != "" {
+		// Generated names already contain the version from the path
+		name = version + "_" + name
+	}
+
+	aliases := make([]string, len(cfg.aliases))
+	for i, alias := range cfg.aliases {
+		if version != "" {
+			alias = version + "_" + alias
+		}
+		aliases[i] = alias
+	}
+
+	// Explicit names and aliases must not belong to another route
+	if explicit {
+		if err := r.names.Check(name, path, method); err != nil {
+			return fmt.Errorf("cannot name %s %s: %w", method, path, err)
+		}
+	}
+	for _, alias := range aliases {
+		if err := r.names.Check(alias, path, method); err != nil {
+			return fmt.Errorf("cannot name %s %s: %w", method, path, err)
+		}
+	}
+
+	if err := r.addRouteLocked(g, method, path, handler, cfg); err != nil {
+		return err
 	}
 
 	// Register named route
 	if name != "" {
-		r.names.Add(name, path, method)
+		// Generated names often collide, as with GET and HEAD on the same
+		// path; the latest route takes the name
+		r.names.Set(name, path, method)
+		if route, ok := r.names.Get(name); ok {
+			route.Version = version
+		}
 	}
+
+	for _, alias := range aliases {
+		r.names.Set(alias, path, method)
+		if route, ok := r.names.Get(alias); ok {
+			route.Version = version
+			route.AliasOf = name
+		}
+	}
+	return nil
+}
+
+// registerAll registers the same handler and options under several paths.
+// Only the first path is the canonical route and receives the route name;
+// the remaining paths are unnamed aliases of it.
+func (r *Router) registerAll(g *Group, method string, paths []string, handler HandlerFunc, cfg *routeConfig) {
+	if len(paths) == 0 {
+		panic(fmt.Sprintf("no paths given for %s route", method))
+	}
+
+	r.register(g, method, paths[0], handler, cfg)
+	for _, path := range paths[1:] {
+		r.addRoute(g, method, path, handler, cfg)
+	}
+}
+
+// addRoute adds a route to the tree without naming it, panicking if it
+// can't
+func (r *Router) addRoute(g *Group, method, path string, handler HandlerFunc, cfg *routeConfig) {
+	if err := r.tryAddRoute(g, method, path, handler, cfg); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryAddRoute adds a route to the tree without naming it
+func (r *Router) tryAddRoute(g *Group, method, path string, handler HandlerFunc, cfg *routeConfig) error {
+	r.routesMu.Lock()
+	defer r.routesMu.Unlock()
+
+	return r.addRouteLocked(g, method, path, handler, cfg)
+}
+
+// addRouteLocked is tryAddRoute for callers holding routesMu
+func (r *Router) addRouteLocked(g *Group, method, path string, handler HandlerFunc, cfg *routeConfig) error {
+	if r.frozen {
+		return fmt.Errorf("cannot register %s %s: the router is frozen", method, path)
+	}
+	path = tree.NormalizePath(path)
+	if err := checkParams(path, handler); err != nil {
+		return err
+	}
+
+	// The route may replace a handler whose chain is cached
+	defer r.chainVersion.Add(1)
+	if cfg.timeout > 0 {
+		handler = timeoutHandler(handler, cfg.timeout)
+	}
+
+	// Convert middleware to interface{} slice for tree package
+	mw := make([]interface{}, 0, len(cfg.middleware)+1)
+	if g != nil {
+		mw = append(mw, g)
+	}
+	for _, m := range cfg.middleware {
+		mw = append(mw, m)
+	}
+
+	// Routes sharing a path across hosts are dispatched by a hostSwitch
+	if cfg.host != "" || r.hosts[routeKey(method, path)] != nil {
+		return r.addHostRoute(method, path, cfg.host, handler, mw)
+	}
+
+	// Add route to tree
+	add := r.tree.AddRoute
+	if r.AllowOverride {
+		add = r.tree.ReplaceRoute
+	}
+	return add(method, path, handler, mw)
 }
 
 // Get registers a GET route with optional configuration.
 //
-// Options can be provided using WithName() and WithMiddleware():
+// Options can be provided using WithName(), WithMiddleware() and WithHost():
 //
 //	r.Get("/users/:id", handler)
 //	r.Get("/users/:id", handler, WithName("user_show"))
@@ -234,118 +566,378 @@ func (r *Router) handle(method, path string, handler HandlerFunc, name string, m
 //
 // Panics on invalid paths (see handle for details).
 func (r *Router) Get(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("GET", path, handler, name, middleware...)
+	r.register(nil, "GET", path, handler, parseRouteOptions(opts))
+}
+
+// GetAll registers a GET route that is reachable under several paths.
+// The handler and options apply to every path. The first path is the
+// canonical one: it receives the route name (given with WithName or
+// generated from that path) and so is the target of reverse routing and
+// generated helpers. The other paths are unnamed aliases.
+//
+//	r.GetAll([]string{"/", "/home", "/index.html"}, homeHandler, WithName("home"))
+//
+// Panics if no paths are given, or on invalid paths (see handle for details).
+func (r *Router) GetAll(paths []string, handler HandlerFunc, opts ...RouteOption) {
+	r.registerAll(nil, "GET", paths, handler, parseRouteOptions(opts))
 }
 
 // Post registers a POST route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Post(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("POST", path, handler, name, middleware...)
+	r.register(nil, "POST", path, handler, parseRouteOptions(opts))
 }
 
 // Put registers a PUT route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Put(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("PUT", path, handler, name, middleware...)
+	r.register(nil, "PUT", path, handler, parseRouteOptions(opts))
 }
 
 // Patch registers a PATCH route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Patch(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("PATCH", path, handler, name, middleware...)
+	r.register(nil, "PATCH", path, handler, parseRouteOptions(opts))
 }
 
 // Delete registers a DELETE route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Delete(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("DELETE", path, handler, name, middleware...)
+	r.register(nil, "DELETE", path, handler, parseRouteOptions(opts))
 }
 
 // Head registers a HEAD route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Head(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("HEAD", path, handler, name, middleware...)
+	r.register(nil, "HEAD", path, handler, parseRouteOptions(opts))
 }
 
 // Options registers an OPTIONS route with optional configuration.
 // See Get() for usage examples.
 // Panics on invalid paths (see handle for details).
 func (r *Router) Options(path string, handler HandlerFunc, opts ...RouteOption) {
-	name, middleware := parseRouteOptions(opts)
-	r.handle("OPTIONS", path, handler, name, middleware...)
+	r.register(nil, "OPTIONS", path, handler, parseRouteOptions(opts))
+}
+
+// Any registers the handler for every standard HTTP method on the path,
+// as for a webhook that accepts whatever method the sender uses:
+//
+//	r.Any("/webhook", handleWebhook)
+//
+// With a wildcard it serves as a catch-all for paths no other route
+// matches, whatever the method:
+//
+//	r.Any("/*path", fallback)
+//
+// Like any duplicate route, a route for one of the methods at the same
+// path can't also be registered unless AllowOverride is set. Only the GET
+// route receives the route name; the others are unnamed.
+// Panics on invalid paths (see handle for details).
+func (r *Router) Any(path string, handler HandlerFunc, opts ...RouteOption) {
+	r.registerMethods(nil, allMethods, path, handler, parseRouteOptions(opts))
+}
+
+// Match registers the handler for each of the given methods on the path,
+// as for a form that is shown with GET and submitted with POST:
+//
+//	r.Match([]string{"GET", "POST"}, "/contact", contactForm)
+//
+// Methods are the standard uppercase names, such as "GET" and "DELETE".
+// Only the route for the first method receives the route name; the others
+// are unnamed.
+//
+// Panics on an unknown method, on an empty list, and on invalid paths (see
+// handle for details).
+func (r *Router) Match(methods []string, path string, handler HandlerFunc, opts ...RouteOption) {
+	r.registerMethods(nil, methods, path, handler, parseRouteOptions(opts))
+}
+
+// registerMethods registers a handler for several methods, naming only the
+// route for the first
+func (r *Router) registerMethods(g *Group, methods []string, path string, handler HandlerFunc, cfg *routeConfig) {
+	if len(methods) == 0 {
+		panic(fmt.Sprintf("no methods given for route %s", path))
+	}
+	for _, method := range methods {
+		if !slices.Contains(allMethods, method) {
+			panic(fmt.Sprintf("cannot register %s %s: unknown HTTP method", method, path))
+		}
+	}
+
+	r.register(g, methods[0], path, handler, cfg)
+	for _, method := range methods[1:] {
+		r.addRoute(g, method, path, handler, cfg)
+	}
+}
+
+// TryGet registers a GET route like Get, but returns an error instead of
+// panicking if the route can't be registered, such as for a malformed
+// path, a duplicate route or a route name that is already taken. Use the
+// Try variants when routes come from configuration or user input:
+//
+//	for _, page := range pages {
+//	    if err := r.TryGet(page.Path, servePage); err != nil {
+//	        return fmt.Errorf("page %s: %w", page.Title, err)
+//	    }
+//	}
+//
+// Nothing is registered when an error is returned.
+func (r *Router) TryGet(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "GET", path, handler, parseRouteOptions(opts))
+}
+
+// TryPost registers a POST route, returning an error instead of panicking.
+// See TryGet.
+func (r *Router) TryPost(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "POST", path, handler, parseRouteOptions(opts))
+}
+
+// TryPut registers a PUT route, returning an error instead of panicking.
+// See TryGet.
+func (r *Router) TryPut(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "PUT", path, handler, parseRouteOptions(opts))
+}
+
+// TryPatch registers a PATCH route, returning an error instead of
+// panicking. See TryGet.
+func (r *Router) TryPatch(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "PATCH", path, handler, parseRouteOptions(opts))
+}
+
+// TryDelete registers a DELETE route, returning an error instead of
+// panicking. See TryGet.
+func (r *Router) TryDelete(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "DELETE", path, handler, parseRouteOptions(opts))
+}
+
+// TryHead registers a HEAD route, returning an error instead of panicking.
+// See TryGet.
+func (r *Router) TryHead(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "HEAD", path, handler, parseRouteOptions(opts))
+}
+
+// TryOptions registers an OPTIONS route, returning an error instead of
+// panicking. See TryGet.
+func (r *Router) TryOptions(path string, handler HandlerFunc, opts ...RouteOption) error {
+	return r.tryRegister(nil, "OPTIONS", path, handler, parseRouteOptions(opts))
 }
 
 // ServeHTTP implements the http.Handler interface
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// Limit the body before any middleware can read it
+	if r.MaxBodyBytes > 0 && req.Body != nil {
+		req.Body = http.MaxBytesReader(w, req.Body, r.MaxBodyBytes)
+	}
+
+	// Take a context from the pool; it goes back once the request is done
+	c := contextPool.Get().(*Context)
+	c.reset(w, req)
+	c.router = r
+	defer releaseContext(c)
+
+	// Reject oversized headers before doing any routing work
+	if r.headersTooLarge(req.Header) {
+		if r.ErrorHandler != nil {
+			r.ErrorHandler(c, NewStatusError(http.StatusRequestHeaderFieldsTooLarge, ""))
+		}
+		return
+	}
+
+	// Let hooks rewrite the request before it's routed
+	for _, hook := range r.PreDispatch {
+		hook(req)
+	}
+
 	path := req.URL.Path
 	method := req.Method
 
-	// Create context
-	c := newContext(w, req)
-
 	// Find the matching route
-	handler, params, middlewareList := r.tree.Find(method, path)
+	node, params := r.tree.Lookup(method, path)
+
+	if node == nil {
+		methods := r.tree.GetMethods(path)
+
+		// Answer OPTIONS for paths that only have other methods. Like the
+		// 405 and 404 responses below, this goes through the global
+		// middleware, so logging sees it and CORS can answer preflight
+		// requests.
+		if method == http.MethodOptions && r.HandleOPTIONS && len(methods) > 0 {
+			r.serve(c, func(c *Context) error {
+				c.SetHeader("Allow", r.allowHeader(methods))
+				return c.NoContent(http.StatusNoContent)
+			})
+			return
+		}
 
-	if handler == nil {
 		// Check if route exists for a different method
-		if r.tree.HasMethod(path) {
-			if err := r.MethodNotAllowed(c); err != nil && r.ErrorHandler != nil {
-				r.ErrorHandler(c, err)
-			}
+		if len(methods) > 0 {
+			// RFC 9110 requires 405 responses to list the allowed methods
+			c.SetHeader("Allow", r.allowHeader(methods))
+			r.serve(c, r.MethodNotAllowed)
 			return
 		}
 
-		if err := r.NotFound(c); err != nil && r.ErrorHandler != nil {
-			r.ErrorHandler(c, err)
+		r.serve(c, r.notFoundHandler(path))
+		return
+	}
+
+	// Send clients to the canonical form of the path
+	if r.RedirectTrailingSlash && node.NType != tree.Wildcard && path != "/" &&
+		strings.HasSuffix(path, "/") != node.TrailingSlash {
+		r.redirectTrailingSlash(c)
+		return
+	}
+
+	// Normalize the wildcard capture if requested
+	if r.CleanWildcardPaths && node.NType == tree.Wildcard {
+		params[node.ParamName] = cleanWildcardPath(params[node.ParamName])
+	}
+
+	// Set params on context; static routes keep the Context's empty map
+	if params != nil {
+		c.Params = params
+	}
+	c.pattern = node.Pattern
+
+	// Let the application refuse a body before the client sends it
+	if r.ExpectContinueHandler != nil && strings.EqualFold(req.Header.Get("Expect"), "100-continue") {
+		if ok, status := r.ExpectContinueHandler(c); !ok {
+			if status == 0 {
+				status = http.StatusExpectationFailed
+			}
+			// The unread body makes the connection unusable for another request
+			c.SetHeader("Connection", "close")
+			if r.ErrorHandler != nil {
+				r.ErrorHandler(c, NewStatusError(status, ""))
+			}
+			return
 		}
+	}
+
+	r.run(c, r.chain(node, method))
+}
+
+// contextPool holds Contexts for reuse across requests, to save allocating
+// one and its maps for every request
+var contextPool = sync.Pool{
+	New: func() interface{} { return new(Context) },
+}
+
+// releaseContext returns c to the pool, unless something is still using it
+func releaseContext(c *Context) {
+	if c.retained {
 		return
 	}
+	c.reset(nil, nil)
+	contextPool.Put(c)
+}
 
-	// Set params on context
-	c.Params = params
+// chainKey identifies the route whose composed chain is cached in
+// Router.chains
+type chainKey struct {
+	node   *tree.Node
+	method string
+}
 
-	// Convert handler from interface{}
-	h := handler.(HandlerFunc)
+// cachedChain is a route's handler wrapped in its middleware, as of a
+// chainVersion
+type cachedChain struct {
+	version uint64
+	handler HandlerFunc
+}
 
-	// Convert middleware from []interface{}
-	routeMiddleware := make([]MiddlewareFunc, len(middlewareList))
-	for i, mw := range middlewareList {
-		routeMiddleware[i] = mw.(MiddlewareFunc)
+// chain returns the handler for a route wrapped in the global, group and
+// route middleware. The composed chain is built on the route's first
+// request and reused until middleware is added or routes change.
+func (r *Router) chain(node *tree.Node, method string) HandlerFunc {
+	version := r.chainVersion.Load()
+	key := chainKey{node, method}
+	if cached, ok := r.chains.Load(key); ok && cached.(*cachedChain).version == version {
+		return cached.(*cachedChain).handler
 	}
 
-	// Build middleware chain (global + route-specific)
-	finalHandler := h
+	// Convert handler from interface{}
+	h := node.Handlers[method].(HandlerFunc)
+
+	// Convert middleware from []interface{}
+	routeMiddleware := resolveMiddleware(node.Middleware)
 
-	// Apply route-specific middleware first (innermost)
+	// Apply group and route-specific middleware first (innermost)
 	for i := len(routeMiddleware) - 1; i >= 0; i-- {
-		finalHandler = routeMiddleware[i](finalHandler)
+		h = routeMiddleware[i](h)
 	}
+	h = r.wrap(h)
+
+	r.chains.Store(key, &cachedChain{version: version, handler: h})
+	return h
+}
+
+// serve runs h inside the global middleware and passes any error it
+// returns to the ErrorHandler
+func (r *Router) serve(c *Context, h HandlerFunc) {
+	r.run(c, r.wrap(h))
+}
 
+// wrap wraps h in the global middleware
+func (r *Router) wrap(h HandlerFunc) HandlerFunc {
 	// Apply global middleware (outermost)
 	for i := len(r.middleware) - 1; i >= 0; i-- {
-		finalHandler = r.middleware[i](finalHandler)
+		h = r.middleware[i](h)
+	}
+	return h
+}
+
+// run executes h and passes any error it returns to the ErrorHandler
+func (r *Router) run(c *Context, h HandlerFunc) {
+	var bw *bufferWriter
+	if r.BufferResponses {
+		bw = bufferResponse(c)
 	}
 
-	// Execute the handler and handle any errors
-	if err := finalHandler(c); err != nil && r.ErrorHandler != nil {
+	err := h(c)
+	if bw != nil {
+		// A failed handler's response is dropped for the error response
+		c.Writer = bw.dst
+		if err == nil {
+			bw.commit()
+		}
+	}
+
+	// Send a status set with SetStatus even if nothing was written
+	if err == nil && !c.Writer.wroteHeader && c.Writer.status != http.StatusOK {
+		c.Writer.WriteHeader(c.Writer.status)
+	}
+
+	if err != nil && r.ErrorHandler != nil {
 		r.ErrorHandler(c, err)
 	}
 }
 
-// GenerateRoutes generates type-safe route helpers
-func (r *Router) GenerateRoutes(packageName, outputFile string) error {
-	rh := routehelper.New()
+// resolveMiddleware converts the middleware stored in the tree for a route.
+// Entries are either MiddlewareFunc values or the *Group the route was
+// registered on, which expands to the group's current middleware chain.
+func resolveMiddleware(list []interface{}) []MiddlewareFunc {
+	middleware := make([]MiddlewareFunc, 0, len(list))
+	for _, mw := range list {
+		switch m := mw.(type) {
+		case *Group:
+			middleware = append(middleware, m.chain()...)
+		case MiddlewareFunc:
+			middleware = append(middleware, m)
+		}
+	}
+	return middleware
+}
+
+// GenerateRoutes generates type-safe route helpers.
+// Options such as routehelper.WithRoutesStruct change the generated code.
+func (r *Router) GenerateRoutes(packageName, outputFile string, opts ...routehelper.Option) error {
+	rh := routehelper.New(opts...)
 
 	// Get all named routes
 	namedRoutes := r.names.All()
@@ -353,23 +945,168 @@ func (r *Router) GenerateRoutes(packageName, outputFile string) error {
 	// print out all named routes
 	fmt.Printf("Generating route helpers for %d named routes...\n", len(namedRoutes))
 
-	for name, route := range namedRoutes {
+	// Add routes in name order so the output is the same on every run
+	names := make([]string, 0, len(namedRoutes))
+	for name := range namedRoutes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		route := namedRoutes[name]
 		rh.AddRoute(name, route.Pattern, route.Method)
 	}
 	return rh.Generate(packageName, outputFile)
 }
 
+// Route describes a named route, as returned by NamedRoutes and passed to
+// Walk
+type Route = naming.Route
+
 // NamedRoutes returns all named routes (useful for testing and introspection)
-func (r *Router) NamedRoutes() map[string]*naming.Route {
+func (r *Router) NamedRoutes() map[string]*Route {
 	return r.names.All()
 }
 
+// RouteExists reports whether a request with method and path would be
+// handled by a route, without running anything. Parameter constraints are
+// checked, but routes limited by WithHost count as existing for every host.
+// It only reads the route tree, so it is safe to call concurrently with
+// serving.
+func (r *Router) RouteExists(method, path string) bool {
+	node, _ := r.tree.Lookup(method, path)
+	return node != nil
+}
+
+// TreeStats describes the shape of the route tree, as returned by Stats
+type TreeStats = tree.TreeStats
+
+// Stats returns the number of nodes in the route tree, its depth, and the
+// number of routes registered for each method, for debugging
+func (r *Router) Stats() TreeStats {
+	return r.tree.Stats()
+}
+
+// PrintRoutes writes a table of every registered route to w, with its
+// method, pattern and name (if it has one), sorted by pattern:
+//
+//	r.PrintRoutes(os.Stdout)
+//
+//	METHOD  PATTERN     NAME
+//	GET     /users      users_index
+//	POST    /users      users_create
+//	GET     /users/:id  users_show
+func (r *Router) PrintRoutes(w io.Writer) error {
+	type row struct{ method, pattern, name string }
+	var rows []row
+	r.Walk(func(method, pattern string, route *Route) {
+		name := ""
+		if route != nil {
+			name = route.Name
+		}
+		rows = append(rows, row{method, pattern, name})
+	})
+	slices.SortStableFunc(rows, func(a, b row) int {
+		return strings.Compare(a.pattern, b.pattern)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME")
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", row.method, row.pattern, row.name)
+	}
+	return tw.Flush()
+}
+
+// Walk calls fn for every registered route, named or not, with its method
+// and pattern. route is the route's entry in NamedRoutes, or nil if the
+// route has no name; names added with WithAliases aren't passed. Routes
+// are visited method by method, in the order each method was first
+// registered.
+//
+//	r.Walk(func(method, pattern string, route *Route) {
+//	    fmt.Println(method, pattern)
+//	})
+//
+// fn must not register routes.
+func (r *Router) Walk(fn func(method, pattern string, route *Route)) {
+	// The tree drops a trailing slash from its patterns
+	names := make(map[string]*Route)
+	for _, route := range r.names.All() {
+		if route.AliasOf == "" {
+			names[route.Method+" /"+strings.Trim(route.Pattern, "/")] = route
+		}
+	}
+
+	r.tree.Walk(func(method string, n *tree.Node) {
+		fn(method, n.Pattern, names[method+" "+n.Pattern])
+	})
+}
+
+// URLFor builds the path for a named route at runtime, filling its :param
+// and *wildcard segments in order with params. Values are path-escaped; a
+// *wildcard value keeps its slashes. It returns an error if there is no
+// route with the name or the number of params doesn't match the pattern.
+// An optional :param? segment is dropped when its value is missing or empty.
+//
+//	r.Get("/users/:id", showUser, WithName("user_show"))
+//	path, err := r.URLFor("user_show", "42") // "/users/42"
+//
+// The generated route helpers are the type-checked alternative for names
+// known at compile time.
+func (r *Router) URLFor(name string, params ...string) (string, error) {
+	route, ok := r.names.Get(name)
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+
+	segments := strings.Split(strings.Trim(route.Pattern, "/"), "/")
+	n := 0
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		// An optional last param may be left out or empty
+		optional := strings.HasSuffix(segment, "?")
+		if optional && (n == len(params) || params[n] == "") {
+			segments = segments[:i]
+			if n < len(params) {
+				n++
+			}
+			break
+		}
+		if n == len(params) {
+			return "", fmt.Errorf("route %q (%s) needs more than %d params", name, route.Pattern, len(params))
+		}
+
+		if segment[0] == '*' {
+			parts := strings.Split(params[n], "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(params[n])
+		}
+		n++
+	}
+
+	if n != len(params) {
+		return "", fmt.Errorf("route %q (%s) takes %d params, got %d", name, route.Pattern, n, len(params))
+	}
+	return "/" + strings.Join(segments, "/"), nil
+}
+
 // ServeConfig holds configuration for the Serve method
 type ServeConfig struct {
 	Port             string
 	GenerateRoutes   bool
 	RoutesPackage    string
 	RoutesOutputFile string
+	HelperOptions    []routehelper.Option
+	ShutdownTimeout  time.Duration
+	CertFile         string
+	KeyFile          string
 }
 
 // ServeOption is a functional option for configuring Serve
@@ -405,10 +1142,105 @@ func WithRoutesOutputFile(file string) ServeOption {
 	}
 }
 
-// listenAndServe is an internal helper that starts the HTTP server.
-// Users should use Serve() instead, or http.ListenAndServe(addr, router) for direct control.
-func (r *Router) listenAndServe(addr string) error {
-	return http.ListenAndServe(addr, r)
+// WithHelperOptions sets options for route helper generation, such as
+// routehelper.WithRoutesStruct()
+func WithHelperOptions(opts ...routehelper.Option) ServeOption {
+	return func(c *ServeConfig) {
+		c.HelperOptions = append(c.HelperOptions, opts...)
+	}
+}
+
+// WithShutdownTimeout sets how long Serve waits for in-flight requests to
+// finish after SIGINT or SIGTERM before closing their connections.
+// The default is 10 seconds.
+func WithShutdownTimeout(d time.Duration) ServeOption {
+	return func(c *ServeConfig) {
+		c.ShutdownTimeout = d
+	}
+}
+
+// WithTLS serves HTTPS using the certificate and private key in the given
+// PEM files, as with http.ListenAndServeTLS. For a certificate signed by a
+// CA, certFile should be the certificate followed by the CA's.
+func WithTLS(certFile, keyFile string) ServeOption {
+	return func(c *ServeConfig) {
+		c.CertFile = certFile
+		c.KeyFile = keyFile
+	}
+}
+
+// redirectTrailingSlash redirects to the request path with its trailing
+// slash added or removed
+func (r *Router) redirectTrailingSlash(c *Context) {
+	// Keep the path escaped, so that characters such as %3F stay part of
+	// it, and collapse leading slashes so the target can't become a
+	// protocol-relative URL like //evil.example
+	path := "/" + strings.TrimLeft(c.Request.URL.EscapedPath(), "/")
+	if strings.HasSuffix(path, "/") {
+		path = strings.TrimRight(path, "/")
+	} else {
+		path += "/"
+	}
+	if q := c.Request.URL.RawQuery; q != "" {
+		path += "?" + q
+	}
+
+	status := http.StatusPermanentRedirect
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		status = http.StatusMovedPermanently
+	}
+	c.Redirect(status, path)
+}
+
+// allowHeader builds an Allow header value from the methods registered for
+// a path, sorted for a stable response. OPTIONS is included when
+// HandleOPTIONS answers it.
+func (r *Router) allowHeader(methods []string) string {
+	allowed := slices.Clone(methods)
+	if r.HandleOPTIONS {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	slices.Sort(allowed)
+	return strings.Join(slices.Compact(allowed), ", ")
+}
+
+// headersTooLarge reports whether header exceeds MaxHeaderCount or
+// MaxHeaderBytes. Each line is counted as "Name: value\r\n".
+func (r *Router) headersTooLarge(header http.Header) bool {
+	if r.MaxHeaderCount <= 0 && r.MaxHeaderBytes <= 0 {
+		return false
+	}
+
+	count, size := 0, 0
+	for name, values := range header {
+		count += len(values)
+		for _, value := range values {
+			size += len(name) + len(value) + 4
+		}
+	}
+
+	return (r.MaxHeaderCount > 0 && count > r.MaxHeaderCount) ||
+		(r.MaxHeaderBytes > 0 && size > r.MaxHeaderBytes)
+}
+
+// Server returns the http.Server that Serve runs, creating it on first
+// use. Its fields can be set before calling Serve, to configure timeouts
+// for example, and it can be shut down from elsewhere:
+//
+//	srv := r.Server()
+//	srv.ReadHeaderTimeout = 5 * time.Second
+//	r.Serve(WithPort(":8080"))
+//
+// Serve sets the server's Addr, and its Handler and MaxHeaderBytes when
+// they aren't set.
+func (r *Router) Server() *http.Server {
+	r.serverMu.Lock()
+	defer r.serverMu.Unlock()
+
+	if r.server == nil {
+		r.server = &http.Server{}
+	}
+	return r.server
 }
 
 // Serve starts the HTTP server with optional configuration and automatic route generation.
@@ -426,6 +1258,13 @@ func (r *Router) listenAndServe(addr string) error {
 //	r.Serve(WithPort(":8080"))                         // Custom port
 //	r.Serve(WithGenerateHelpers(false))                // Disable helper generation
 //	r.Serve(WithPort(":8080"), WithGenerateHelpers(true)) // Production with helper generation
+//	r.Serve(WithPort(":443"), WithTLS("cert.pem", "key.pem")) // HTTPS
+//
+// Serve blocks until the process receives SIGINT or SIGTERM, then shuts the
+// server down gracefully: it stops accepting connections and waits for
+// in-flight requests to finish, for up to the shutdown timeout (see
+// WithShutdownTimeout). It returns nil after a clean shutdown, or the error
+// that stopped the server.
 func (r *Router) Serve(opts ...ServeOption) error {
 	env := os.Getenv("ROUTER_ENV")
 	isProduction := env == "production"
@@ -436,6 +1275,7 @@ func (r *Router) Serve(opts ...ServeOption) error {
 		GenerateRoutes:   !isProduction, // Auto-generate in development
 		RoutesPackage:    "routes",
 		RoutesOutputFile: "routes/generated.go",
+		ShutdownTimeout:  10 * time.Second,
 	}
 
 	// Apply user options (can override defaults)
@@ -443,15 +1283,81 @@ func (r *Router) Serve(opts ...ServeOption) error {
 		opt(config)
 	}
 
+	// Catch a missing certificate before doing anything else
+	tls := config.CertFile != "" || config.KeyFile != ""
+	if tls {
+		if err := checkTLSFiles(config.CertFile, config.KeyFile); err != nil {
+			return err
+		}
+	}
+
 	// Generate route helpers if enabled
 	if config.GenerateRoutes {
 		fmt.Println("Generating route helpers...")
-		if err := r.GenerateRoutes(config.RoutesPackage, config.RoutesOutputFile); err != nil {
+		if err := r.GenerateRoutes(config.RoutesPackage, config.RoutesOutputFile, config.HelperOptions...); err != nil {
 			return fmt.Errorf("failed to generate routes: %w", err)
 		}
 		fmt.Println("Route generation complete!")
 	}
 
-	fmt.Printf("Starting server on http://localhost%s\n", config.Port)
-	return r.listenAndServe(config.Port)
+	server := r.Server()
+	server.Addr = config.Port
+	if server.Handler == nil {
+		server.Handler = r
+	}
+	if server.MaxHeaderBytes == 0 {
+		server.MaxHeaderBytes = r.MaxHeaderBytes
+	}
+
+	// Listen for signals before starting, so none are missed
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	errs := make(chan error, 1)
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	go func() {
+		if tls {
+			errs <- server.ListenAndServeTLS(config.CertFile, config.KeyFile)
+			return
+		}
+		errs <- server.ListenAndServe()
+	}()
+
+	fmt.Printf("Starting server on %s://localhost%s\n", scheme, config.Port)
+
+	select {
+	case err := <-errs:
+		// Shut down through Server() rather than by a signal
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-quit:
+	}
+
+	fmt.Println("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// checkTLSFiles reports a clear error if the certificate or key file for
+// WithTLS is missing
+func checkTLSFiles(certFile, keyFile string) error {
+	for _, f := range []struct{ kind, path string }{
+		{"certificate", certFile},
+		{"key", keyFile},
+	} {
+		if f.path == "" {
+			return fmt.Errorf("TLS %s file not set", f.kind)
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			return fmt.Errorf("TLS %s file: %w", f.kind, err)
+		}
+	}
+	return nil
 }
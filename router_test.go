@@ -1,12 +1,30 @@
 package router
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestStaticRoutes(t *testing.T) {
@@ -129,6 +147,39 @@ func TestWildcards(t *testing.T) {
 	}
 }
 
+func TestCleanWildcardPaths(t *testing.T) {
+	tests := []struct {
+		clean bool
+		path  string
+		want  string
+	}{
+		{false, "/files/foo/", "foo"},
+		{false, "/files/a//b/./c", "a//b/./c"},
+		{true, "/files/a//b/./c", "a/b/c"},
+		{true, "/files/a/../../etc/passwd", "etc/passwd"},
+		{true, "/files/docs/readme.md/", "docs/readme.md"},
+	}
+
+	for _, tt := range tests {
+		r := New()
+		r.CleanWildcardPaths = tt.clean
+
+		var captured string
+		r.Get("/files/*filepath", func(c *Context) error {
+			captured = c.Param("filepath")
+			return nil
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.URL.Path = tt.path
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		if captured != tt.want {
+			t.Errorf("clean=%v %s: expected %q, got %q", tt.clean, tt.path, tt.want, captured)
+		}
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	r := New()
 
@@ -277,6 +328,52 @@ func TestGroupMiddleware(t *testing.T) {
 	}
 }
 
+func TestGroupUseAfterRegistration(t *testing.T) {
+	r := New()
+
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				calls = append(calls, name)
+				return next(c)
+			}
+		}
+	}
+
+	api := r.Group("/api", record("api"))
+	v1 := api.Group("/v1", record("v1"))
+
+	v1.Get("/test", func(c *Context) error {
+		calls = append(calls, "handler")
+		return c.String(http.StatusOK, "OK")
+	}, WithMiddleware(record("route")))
+	api.Resources("/users", &TestController{}, Only(IndexAction))
+
+	// Added after the routes above were registered
+	api.Use(record("api-late"))
+	v1.Use(record("v1-late"))
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/api/v1/test", []string{"api", "api-late", "v1", "v1-late", "route", "handler"}},
+		{"/api/users", []string{"api", "api-late"}},
+	}
+
+	for _, tt := range tests {
+		calls = nil
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if strings.Join(calls, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("GET %s: expected calls %v, got %v", tt.path, tt.expected, calls)
+		}
+	}
+}
+
 func TestAllHTTPMethods(t *testing.T) {
 	r := New()
 
@@ -566,119 +663,1917 @@ func TestConcurrentRequests(t *testing.T) {
 	}
 }
 
-// Benchmarks
-
-func BenchmarkStaticRoutes(b *testing.B) {
+func TestWhen(t *testing.T) {
 	r := New()
-	r.Get("/users", func(c *Context) error {
-		return c.String(http.StatusOK, "users")
-	})
 
-	req := httptest.NewRequest("GET", "/users", nil)
+	handler := func(c *Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	r.When(true).Get("/enabled", handler)
+	r.When(false).Get("/disabled", handler)
 
-	for i := 0; i < b.N; i++ {
+	api := r.Group("/api")
+	api.When(true).Get("/enabled", handler)
+	api.When(false).Get("/disabled", handler)
+	api.When(false).Group("/nested").Get("/disabled", handler)
+	api.When(false).Resources("/users", &TestController{})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/enabled", http.StatusOK},
+		{"/disabled", http.StatusNotFound},
+		{"/api/enabled", http.StatusOK},
+		{"/api/disabled", http.StatusNotFound},
+		{"/api/nested/disabled", http.StatusNotFound},
+		{"/api/users", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("GET %s: got %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+
+	if _, ok := r.NamedRoutes()["disabled_index"]; ok {
+		t.Error("disabled route should not be named")
 	}
 }
 
-func BenchmarkParameterRoutes(b *testing.B) {
+func TestNotFoundMatchesHandlerStatusError(t *testing.T) {
 	r := New()
+
 	r.Get("/users/:id", func(c *Context) error {
-		id := c.Param("id")
-		return c.String(http.StatusOK, id)
+		return NotFound("Not Found")
 	})
 
-	req := httptest.NewRequest("GET", "/users/123", nil)
+	routed := httptest.NewRecorder()
+	r.ServeHTTP(routed, httptest.NewRequest("GET", "/missing", nil))
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	returned := httptest.NewRecorder()
+	r.ServeHTTP(returned, httptest.NewRequest("GET", "/users/1", nil))
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		r.ServeHTTP(w, req)
+	if routed.Code != http.StatusNotFound || returned.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for both, got %d and %d", routed.Code, returned.Code)
+	}
+
+	if routed.Body.String() != returned.Body.String() {
+		t.Errorf("expected identical bodies, got %q and %q", routed.Body.String(), returned.Body.String())
 	}
 }
 
-func BenchmarkWildcardRoutes(b *testing.B) {
+func TestErrorHandlerUsesStatusErrorCode(t *testing.T) {
 	r := New()
-	r.Get("/files/*filepath", func(c *Context) error {
-		path := c.Param("filepath")
-		return c.String(http.StatusOK, path)
+
+	r.Get("/teapot", func(c *Context) error {
+		return NewStatusError(http.StatusTeapot, "")
+	})
+	r.Get("/wrapped", func(c *Context) error {
+		return fmt.Errorf("lookup failed: %w", NotFound("user not found"))
 	})
 
-	req := httptest.NewRequest("GET", "/files/some/deep/path/file.txt", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/teapot", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected status 418, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "I'm a teapot") {
+		t.Errorf("expected default status text in body, got %q", w.Body.String())
+	}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/wrapped", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for wrapped StatusError, got %d", w.Code)
+	}
+}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		r.ServeHTTP(w, req)
+func TestMethodNotAllowedBody(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error { return nil })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+	if strings.TrimSpace(w.Body.String()) != `{"error":"Method Not Allowed"}` {
+		t.Errorf("unexpected body %q", w.Body.String())
 	}
 }
 
-func BenchmarkComplexRouting(b *testing.B) {
+func TestExpectContinueHandler(t *testing.T) {
 	r := New()
 
-	// Multiple routes to simulate realistic routing
-	r.Get("/", func(c *Context) error { return c.String(http.StatusOK, "home") })
-	r.Get("/about", func(c *Context) error { return c.String(http.StatusOK, "about") })
-	r.Get("/contact", func(c *Context) error { return c.String(http.StatusOK, "contact") })
-	r.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "users") })
-	r.Get("/users/:id", func(c *Context) error { return c.String(http.StatusOK, "user") })
-	r.Get("/users/:id/posts", func(c *Context) error { return c.String(http.StatusOK, "posts") })
-	r.Get("/users/:id/posts/:post_id", func(c *Context) error { return c.String(http.StatusOK, "post") })
-	r.Get("/files/*filepath", func(c *Context) error { return c.String(http.StatusOK, "file") })
+	r.ExpectContinueHandler = func(c *Context) (bool, int) {
+		if c.Param("bucket") == "locked" {
+			return false, http.StatusForbidden
+		}
+		if c.Request.ContentLength > 10 {
+			return false, 0
+		}
+		return true, 0
+	}
 
-	req := httptest.NewRequest("GET", "/users/42/posts/123", nil)
+	handlerCalled := false
+	r.Post("/uploads/:bucket", func(c *Context) error {
+		handlerCalled = true
+		return c.NoContent(http.StatusCreated)
+	})
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	tests := []struct {
+		path    string
+		body    string
+		expect  string
+		code    int
+		handled bool
+	}{
+		{"/uploads/photos", "small", "100-continue", http.StatusCreated, true},
+		{"/uploads/photos", "much too large", "100-continue", http.StatusExpectationFailed, false},
+		{"/uploads/locked", "small", "100-continue", http.StatusForbidden, false},
+		{"/uploads/photos", "much too large", "", http.StatusCreated, true},
+	}
 
-	for i := 0; i < b.N; i++ {
+	for _, tt := range tests {
+		handlerCalled = false
+		req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+		if tt.expect != "" {
+			req.Header.Set("Expect", tt.expect)
+		}
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("POST %s (%q): expected status %d, got %d", tt.path, tt.body, tt.code, w.Code)
+		}
+		if handlerCalled != tt.handled {
+			t.Errorf("POST %s (%q): expected handler called=%v", tt.path, tt.body, tt.handled)
+		}
+		if !tt.handled && w.Header().Get("Connection") != "close" {
+			t.Errorf("POST %s (%q): expected Connection: close on rejection", tt.path, tt.body)
+		}
 	}
 }
 
-func BenchmarkMiddlewareChain(b *testing.B) {
+func TestWithHost(t *testing.T) {
 	r := New()
 
-	// Add multiple middleware
-	middleware1 := func(next HandlerFunc) HandlerFunc {
-		return func(c *Context) error {
-			return next(c)
-		}
+	text := func(body string) HandlerFunc {
+		return func(c *Context) error { return c.String(http.StatusOK, body) }
 	}
-	middleware2 := func(next HandlerFunc) HandlerFunc {
-		return func(c *Context) error {
-			return next(c)
+
+	r.Get("/", text("public"))
+	r.Get("/", text("tenant"), WithHost("*.example.com"))
+	r.Get("/", text("admin"), WithHost("admin.example.com"))
+	r.Get("/dashboard", text("dashboard"), WithHost("admin.example.com"))
+
+	tests := []struct {
+		host     string
+		path     string
+		expected int
+		body     string
+	}{
+		{"admin.example.com", "/", http.StatusOK, "admin"},
+		{"ADMIN.example.com:8080", "/", http.StatusOK, "admin"},
+		{"acme.example.com", "/", http.StatusOK, "tenant"},
+		{"example.com", "/", http.StatusOK, "public"},
+		{"other.org", "/", http.StatusOK, "public"},
+		{"admin.example.com", "/dashboard", http.StatusOK, "dashboard"},
+		{"acme.example.com", "/dashboard", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithHostMiddleware(t *testing.T) {
+	r := New()
+
+	var calls []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				calls = append(calls, name)
+				return next(c)
+			}
 		}
 	}
-	middleware3 := func(next HandlerFunc) HandlerFunc {
-		return func(c *Context) error {
-			return next(c)
+
+	admin := r.Group("/admin", mark("group"))
+	admin.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "admin") },
+		WithHost("admin.example.com"), WithMiddleware(mark("route")))
+	admin.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "public") })
+
+	req := httptest.NewRequest("GET", "/admin/users", nil)
+	req.Host = "admin.example.com"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "admin" {
+		t.Errorf("expected admin route, got %q", w.Body.String())
+	}
+	if len(calls) != 2 || calls[0] != "group" || calls[1] != "route" {
+		t.Errorf("expected group then route middleware, got %v", calls)
+	}
+
+	calls = nil
+	req = httptest.NewRequest("GET", "/admin/users", nil)
+	req.Host = "www.example.com"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "public" {
+		t.Errorf("expected public route, got %q", w.Body.String())
+	}
+	if len(calls) != 1 || calls[0] != "group" {
+		t.Errorf("expected only group middleware, got %v", calls)
+	}
+}
+
+func TestWithHostInvalidWildcard(t *testing.T) {
+	r := New()
+
+	defer func() {
+		if rec := recover(); rec == nil {
+			t.Error("expected panic for a wildcard that is not a leading *.")
 		}
+	}()
+
+	r.Get("/", func(c *Context) error { return nil }, WithHost("admin.*.com"))
+}
+
+func TestMaxHeaderCount(t *testing.T) {
+	r := New()
+	r.MaxHeaderCount = 5
+	r.Get("/", func(c *Context) error { return c.String(http.StatusOK, "OK") })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for i := 0; i < 10; i++ {
+		req.Header.Add("X-Flood", strconv.Itoa(i))
 	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
 
-	r.Use(middleware1, middleware2, middleware3)
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected status 431, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Request Header Fields Too Large") {
+		t.Errorf("expected error body from ErrorHandler, got %q", w.Body.String())
+	}
 
-	r.Get("/test", func(c *Context) error {
-		return c.String(http.StatusOK, "test")
-	})
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Small", "1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
 
-	req := httptest.NewRequest("GET", "/test", nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 under the limit, got %d", w.Code)
+	}
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func TestMaxHeaderBytes(t *testing.T) {
+	r := New()
+	r.MaxHeaderBytes = 1024
+	r.Get("/", func(c *Context) error { return c.String(http.StatusOK, "OK") })
 
-	for i := 0; i < b.N; i++ {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Large", strings.Repeat("a", 2048))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected status 431, got %d", w.Code)
+	}
+}
+
+func TestParamConstraints(t *testing.T) {
+	r := New()
+
+	text := func(body string) HandlerFunc {
+		return func(c *Context) error { return c.String(http.StatusOK, body+":"+c.Param("id")) }
+	}
+
+	r.Get("/users/:id(int)", text("int"))
+	r.Get("/users/:id(alpha)", text("alpha"))
+	r.Get("/orders/:id(uuid)", text("uuid"))
+
+	tests := []struct {
+		path     string
+		expected int
+		body     string
+	}{
+		{"/users/42", http.StatusOK, "int:42"},
+		{"/users/-7", http.StatusOK, "int:-7"},
+		{"/users/bob", http.StatusOK, "alpha:bob"},
+		{"/users/bob42", http.StatusNotFound, ""},
+		{"/orders/3f2a6c1e-9b1d-4c8e-a2f0-1234567890ab", http.StatusOK, "uuid:3f2a6c1e-9b1d-4c8e-a2f0-1234567890ab"},
+		{"/orders/42", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParamConstraintFallsThrough(t *testing.T) {
+	r := New()
+
+	r.Get("/posts/:id(int)", func(c *Context) error { return c.String(http.StatusOK, "by id") })
+	r.Get("/posts/:slug", func(c *Context) error { return c.String(http.StatusOK, "by slug") })
+
+	for path, expected := range map[string]string{"/posts/5": "by id", "/posts/hello-world": "by slug"} {
+		req := httptest.NewRequest("GET", path, nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
+
+		if w.Body.String() != expected {
+			t.Errorf("GET %s: expected %q, got %q", path, expected, w.Body.String())
+		}
 	}
 }
 
+func TestParamConstraintUnknown(t *testing.T) {
+	r := New()
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected panic for unknown constraint")
+		}
+		if !strings.Contains(fmt.Sprint(rec), `"float"`) {
+			t.Errorf("expected panic to name the constraint, got %v", rec)
+		}
+	}()
+
+	r.Get("/items/:id(float)", func(c *Context) error { return nil })
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	r := New()
+	r.HandleOPTIONS = true
+
+	handler := func(c *Context) error { return c.String(http.StatusOK, "OK") }
+	r.Get("/users/:id", handler)
+	r.Patch("/users/:id", handler)
+	r.Delete("/users/:id", handler)
+	r.Get("/custom", handler)
+	r.Options("/custom", func(c *Context) error { return c.String(http.StatusOK, "custom") })
+
+	req := httptest.NewRequest("OPTIONS", "/users/123", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, OPTIONS, PATCH" {
+		t.Errorf("expected Allow header %q, got %q", "DELETE, GET, OPTIONS, PATCH", allow)
+	}
+
+	// An explicit OPTIONS route still handles the request
+	req = httptest.NewRequest("OPTIONS", "/custom", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "custom" {
+		t.Errorf("expected the explicit OPTIONS handler to run, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Unknown paths are still 404
+	req = httptest.NewRequest("OPTIONS", "/missing", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown path, got %d", w.Code)
+	}
+}
+
+func TestHandleOPTIONSDisabledByDefault(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error { return nil })
+
+	req := httptest.NewRequest("OPTIONS", "/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 without HandleOPTIONS, got %d", w.Code)
+	}
+}
+
+func TestMethodNotAllowedSetsAllowHeader(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/x", handler)
+	r.Post("/x", handler)
+
+	req := httptest.NewRequest("DELETE", "/x", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("expected Allow header %q, got %q", "GET, POST", allow)
+	}
+
+	// The header is set even when the handler is replaced
+	r.MethodNotAllowed = func(c *Context) error {
+		return c.String(http.StatusMethodNotAllowed, "nope")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("expected Allow header with a custom handler, got %q", allow)
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	r := New()
+	r.RedirectTrailingSlash = true
+
+	handler := func(c *Context) error { return c.String(http.StatusOK, "OK") }
+	r.Get("/users", handler)
+	r.Post("/users", handler)
+	r.Get("/posts/", handler)
+	r.Get("/files/*filepath", handler)
+	r.Get("/docs/:name", handler)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+		location string
+	}{
+		{"GET", "/users", http.StatusOK, ""},
+		{"GET", "/users/", http.StatusMovedPermanently, "/users"},
+		{"GET", "/users/?page=2", http.StatusMovedPermanently, "/users?page=2"},
+		{"POST", "/users/", http.StatusPermanentRedirect, "/users"},
+		{"GET", "/posts/", http.StatusOK, ""},
+		{"GET", "/posts", http.StatusMovedPermanently, "/posts/"},
+		{"GET", "//users/", http.StatusMovedPermanently, "/users"},
+		{"GET", "/files/a/b/", http.StatusOK, ""},
+		{"GET", "/files/a/b", http.StatusOK, ""},
+		{"GET", "/docs/a%3Fb%20c/", http.StatusMovedPermanently, "/docs/a%3Fb%20c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("expected Location %q, got %q", tt.location, location)
+			}
+		})
+	}
+}
+
+func TestTrailingSlashMatchesWithoutRedirect(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "OK") })
+
+	req := httptest.NewRequest("GET", "/users/", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected /users/ to be served by default, got %d", w.Code)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	r := New()
+	r.MaxBodyBytes = 10
+
+	var middlewareErr, bindErr error
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Path() == "/peek" {
+				_, middlewareErr = c.Body()
+			}
+			return next(c)
+		}
+	})
+	r.Post("/bind", func(c *Context) error {
+		var payload map[string]string
+		bindErr = c.BindJSON(&payload)
+		return bindErr
+	})
+	r.Post("/peek", func(c *Context) error { return nil })
+
+	body := `{"data":"` + strings.Repeat("x", 88) + `"}`
+
+	req := httptest.NewRequest("POST", "/bind", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if bindErr == nil {
+		t.Error("expected BindJSON to fail past the limit")
+	}
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/peek", strings.NewReader(body))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if middlewareErr == nil {
+		t.Error("expected middleware body reads to be limited too")
+	}
+}
+
+func TestRouteExists(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/about", handler)
+	r.Get("/users/:id(int)", handler)
+	r.Post("/users/:id/posts", handler)
+	r.Get("/files/*filepath", handler)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected bool
+	}{
+		{"GET", "/about", true},
+		{"GET", "/users/42", true},
+		{"GET", "/users/bob", false},
+		{"POST", "/users/42/posts", true},
+		{"GET", "/users/42/posts", false},
+		{"GET", "/files/docs/readme.md", true},
+		{"GET", "/missing", false},
+		{"DELETE", "/about", false},
+	}
+
+	for _, tt := range tests {
+		if got := r.RouteExists(tt.method, tt.path); got != tt.expected {
+			t.Errorf("RouteExists(%s, %s): expected %v, got %v", tt.method, tt.path, tt.expected, got)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, tt := range tests {
+				r.RouteExists(tt.method, tt.path)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDuplicateRoutePanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		register func(r *Router)
+	}{
+		{"same path", "/x", func(r *Router) {
+			r.Get("/x", func(c *Context) error { return nil })
+			r.Get("/x", func(c *Context) error { return nil })
+		}},
+		{"trailing slash", "/x", func(r *Router) {
+			r.Get("/x", func(c *Context) error { return nil })
+			r.Get("/x/", func(c *Context) error { return nil })
+		}},
+		{"root", "/", func(r *Router) {
+			r.Get("/", func(c *Context) error { return nil })
+			r.Get("/", func(c *Context) error { return nil })
+		}},
+		{"group", "/api/x", func(r *Router) {
+			r.Get("/api/x", func(c *Context) error { return nil })
+			r.Group("/api").Get("/x", func(c *Context) error { return nil })
+		}},
+		{"host", "/x", func(r *Router) {
+			r.Get("/x", func(c *Context) error { return nil }, WithHost("a.example.com"))
+			r.Get("/x", func(c *Context) error { return nil }, WithHost("a.example.com"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					t.Fatal("expected panic for duplicate route")
+				}
+				if msg := fmt.Sprint(rec); !strings.Contains(msg, "GET "+tt.path) {
+					t.Errorf("expected panic to name the method and path, got %q", msg)
+				}
+			}()
+
+			tt.register(New())
+		})
+	}
+}
+
+func TestAllowOverride(t *testing.T) {
+	r := New()
+	r.AllowOverride = true
+
+	r.Get("/x", func(c *Context) error { return c.String(http.StatusOK, "first") })
+	r.Get("/x", func(c *Context) error { return c.String(http.StatusOK, "second") })
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "second" {
+		t.Errorf("expected the later handler to replace the first, got %q", w.Body.String())
+	}
+}
+
+// freeAddr returns a local address with a port that is free to listen on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// waitForServer polls url until the server answers or the test times out
+func waitForServer(t *testing.T, client *http.Client, url string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server at %s did not start", url)
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending os.Interrupt is not supported on Windows")
+	}
+
+	addr := freeAddr(t)
+	r := New()
+	r.Get("/ping", func(c *Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Serve(WithPort(addr), WithGenerateHelpers(false), WithShutdownTimeout(time.Second))
+	}()
+
+	waitForServer(t, http.DefaultClient, "http://"+addr+"/ping")
+	if r.Server().Addr != addr {
+		t.Errorf("expected Server().Addr %q, got %q", addr, r.Server().Addr)
+	}
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after SIGINT")
+	}
+
+	if _, err := http.Get("http://" + addr + "/ping"); err == nil {
+		t.Error("expected the server to be closed")
+	}
+}
+
+func TestServeShutdownThroughServer(t *testing.T) {
+	addr := freeAddr(t)
+	r := New()
+	r.Server().ReadHeaderTimeout = time.Second
+	r.Get("/ping", func(c *Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Serve(WithPort(addr), WithGenerateHelpers(false))
+	}()
+
+	waitForServer(t, http.DefaultClient, "http://"+addr+"/ping")
+	if r.Server().ReadHeaderTimeout != time.Second {
+		t.Error("expected Serve to keep the server's settings")
+	}
+
+	if err := r.Server().Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil after Shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Shutdown")
+	}
+}
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 to dir
+// and returns the certificate and key file paths and a pool trusting it
+func writeSelfSignedCert(t *testing.T, dir string) (string, string, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestServeTLS(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t, t.TempDir())
+
+	addr := freeAddr(t)
+	r := New()
+	r.Get("/ping", func(c *Context) error {
+		return c.String(http.StatusOK, "secure pong")
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Serve(WithPort(addr), WithGenerateHelpers(false), WithTLS(certFile, keyFile))
+	}()
+	defer func() {
+		r.Server().Shutdown(context.Background())
+		<-done
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	waitForServer(t, client, "https://"+addr+"/ping")
+
+	resp, err := client.Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "secure pong" {
+		t.Errorf("expected 200 secure pong, got %d %q", resp.StatusCode, body)
+	}
+	if resp.TLS == nil {
+		t.Error("expected the response to be served over TLS")
+	}
+}
+
+func TestServeTLSMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _, _ := writeSelfSignedCert(t, dir)
+
+	r := New()
+	err := r.Serve(WithPort(freeAddr(t)), WithGenerateHelpers(false), WithTLS(certFile, filepath.Join(dir, "missing.pem")))
+	if err == nil || !strings.Contains(err.Error(), "TLS key file") || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing key file error, got %v", err)
+	}
+
+	err = r.Serve(WithPort(freeAddr(t)), WithGenerateHelpers(false), WithTLS(filepath.Join(dir, "missing.pem"), certFile))
+	if err == nil || !strings.Contains(err.Error(), "TLS certificate file") {
+		t.Errorf("expected a missing certificate file error, got %v", err)
+	}
+}
+
+func TestWildcardMustBeLastSegment(t *testing.T) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected panic for a wildcard before the last segment")
+		}
+		msg := fmt.Sprint(rec)
+		if !strings.Contains(msg, `"*rest"`) || !strings.Contains(msg, "GET /a/*rest/b") {
+			t.Errorf("expected panic to name the wildcard segment and route, got %q", msg)
+		}
+	}()
+
+	r := New()
+	r.Get("/a/*rest/b", func(c *Context) error { return nil })
+}
+
+func TestOptionalParams(t *testing.T) {
+	r := New()
+	r.Get("/posts/:id/:slug?", func(c *Context) error {
+		return c.String(http.StatusOK, "id=%s slug=%s pattern=%s", c.Param("id"), c.Param("slug"), c.pattern)
+	})
+
+	tests := []struct {
+		path     string
+		expected int
+		body     string
+	}{
+		{"/posts/5/hello", http.StatusOK, "id=5 slug=hello pattern=/posts/:id/:slug?"},
+		{"/posts/5", http.StatusOK, "id=5 slug= pattern=/posts/:id/:slug?"},
+		{"/posts", http.StatusNotFound, ""},
+		{"/posts/5/hello/extra", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOptionalParamsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		message string
+	}{
+		{"not last", "/posts/:id?/comments", "must be the last segment"},
+		{"static", "/posts/new?", "only params can be optional"},
+		{"conflicts with existing route", "/users/:id/:tab?", "duplicate route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					t.Fatalf("expected panic registering %s", tt.path)
+				}
+				if msg := fmt.Sprint(rec); !strings.Contains(msg, tt.message) {
+					t.Errorf("expected panic containing %q, got %q", tt.message, msg)
+				}
+			}()
+
+			r := New()
+			r.Get("/users/:id", func(c *Context) error { return nil })
+			r.Get(tt.path, func(c *Context) error { return nil })
+		})
+	}
+}
+
+func TestRegexParamConstraints(t *testing.T) {
+	r := New()
+	r.Get(`/files/:name([a-z0-9_-]+\.png)`, func(c *Context) error {
+		return c.String(http.StatusOK, "image %s", c.Param("name"))
+	})
+
+	tests := []struct {
+		path     string
+		expected int
+		body     string
+	}{
+		{"/files/logo.png", http.StatusOK, "image logo.png"},
+		{"/files/my_logo-2.png", http.StatusOK, "image my_logo-2.png"},
+		{"/files/logo.gif", http.StatusNotFound, ""},
+		{"/files/Logo.png", http.StatusNotFound, ""},
+		// The pattern must match the whole segment
+		{"/files/logo.png.exe", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("GET %s: expected status %d, got %d", tt.path, tt.expected, w.Code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("GET %s: expected %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestParamPrecedence(t *testing.T) {
+	r := New()
+	handler := func(name string) HandlerFunc {
+		return func(c *Context) error {
+			return c.String(http.StatusOK, name)
+		}
+	}
+
+	// Registered in reverse order of precedence
+	r.Get("/items/*rest", handler("wildcard"))
+	r.Get("/items/:id", handler("param"))
+	r.Get(`/items/:id([0-9]+)`, handler("regex"))
+	r.Get("/items/new", handler("static"))
+
+	tests := map[string]string{
+		"/items/new":   "static",
+		"/items/42":    "regex",
+		"/items/abc":   "param",
+		"/items/a/b/c": "wildcard",
+	}
+
+	for path, expected := range tests {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != expected {
+			t.Errorf("GET %s: expected %q, got %q", path, expected, w.Body.String())
+		}
+	}
+}
+
+func TestRegexParamConstraintInvalid(t *testing.T) {
+	r := New()
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected panic for an invalid pattern")
+		}
+		if !strings.Contains(fmt.Sprint(rec), `invalid pattern "[a-z"`) {
+			t.Errorf("expected panic to name the pattern, got %v", rec)
+		}
+	}()
+
+	r.Get("/files/:name([a-z)", func(c *Context) error { return nil })
+}
+
+func TestMiddlewareRunsForUnmatchedRequests(t *testing.T) {
+	r := New()
+
+	var count int
+	var statuses []int
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			count++
+			err := next(c)
+			statuses = append(statuses, statusCode(err))
+			return err
+		}
+	})
+	r.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{"GET", "/missing", http.StatusNotFound},
+		{"POST", "/users", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.expected, w.Code)
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("expected middleware to run for both requests, ran %d times", count)
+	}
+	if len(statuses) != 2 || statuses[0] != http.StatusNotFound || statuses[1] != http.StatusMethodNotAllowed {
+		t.Errorf("expected middleware to see the 404 and 405 errors, got %v", statuses)
+	}
+}
+
+func TestGroupNotFound(t *testing.T) {
+	r := New()
+	r.NotFound = func(c *Context) error {
+		return c.HTML(http.StatusNotFound, "<h1>Page not found</h1>")
+	}
+
+	api := r.Group("/api")
+	api.NotFound = func(c *Context) error {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "no such endpoint"})
+	}
+	api.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	admin := api.Group("/admin")
+	admin.NotFound = func(c *Context) error {
+		return c.String(http.StatusNotFound, "admin: not found")
+	}
+
+	// A group without a NotFound handler defers to its parent
+	api.Group("/v1").Get("/status", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		path        string
+		body        string
+		contentType string
+	}{
+		{"/api/missing", "{\"error\":\"no such endpoint\"}\n", "application/json"},
+		{"/api", "{\"error\":\"no such endpoint\"}\n", "application/json"},
+		{"/api/v1/missing", "{\"error\":\"no such endpoint\"}\n", "application/json"},
+		{"/api/admin/missing", "admin: not found", "text/plain"},
+		{"/missing", "<h1>Page not found</h1>", "text/html; charset=utf-8"},
+		// Prefixes match whole segments only
+		{"/apiary", "<h1>Page not found</h1>", "text/html; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", tt.path, w.Code)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("GET %s: expected %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("GET %s: expected content type %q, got %q", tt.path, tt.contentType, ct)
+		}
+	}
+
+	// Matched routes in the group are unaffected
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "users" {
+		t.Errorf("expected 200 users, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestGroupNotFoundHostRoute(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.NotFound = func(c *Context) error {
+		return c.String(http.StatusNotFound, "api: not found")
+	}
+	api.Get("/status", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	}, WithHost("api.example.com"))
+
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	req.Host = "www.example.com"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound || w.Body.String() != "api: not found" {
+		t.Errorf("expected the group's 404, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error {
+		return c.String(http.StatusOK, c.Param("id"))
+	}
+
+	// Run with -race to check registration is serialized
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g := r.Group(fmt.Sprintf("/plugin%d", i))
+			g.Get("/items/:id", handler)
+			r.Post(fmt.Sprintf("/hooks/%d", i), handler)
+		}(i)
+	}
+	wg.Wait()
+
+	r.Freeze()
+
+	for i := 0; i < 20; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/plugin%d/items/7", i), nil))
+		if w.Code != http.StatusOK || w.Body.String() != "7" {
+			t.Errorf("plugin %d: expected 200 7, got %d %q", i, w.Code, w.Body.String())
+		}
+		if !r.RouteExists("POST", fmt.Sprintf("/hooks/%d", i)) {
+			t.Errorf("expected POST /hooks/%d to be registered", i)
+		}
+	}
+	if len(r.NamedRoutes()) != 40 {
+		t.Errorf("expected 40 named routes, got %d", len(r.NamedRoutes()))
+	}
+}
+
+func TestFreezeRejectsRegistration(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "users") })
+	r.Freeze()
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected registering on a frozen router to panic")
+		}
+		if !strings.Contains(fmt.Sprint(rec), "frozen") {
+			t.Errorf("expected a frozen router panic, got %v", rec)
+		}
+
+		// Existing routes are still served
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+		if w.Body.String() != "users" {
+			t.Errorf("expected users, got %d %q", w.Code, w.Body.String())
+		}
+	}()
+
+	r.Get("/posts", func(c *Context) error { return nil })
+}
+
+func TestContextReuseStartsClean(t *testing.T) {
+	r := New()
+	r.Get("/users/:id", func(c *Context) error {
+		if _, ok := c.Get("seen"); ok {
+			t.Error("expected an empty store on a new request")
+		}
+		c.Set("seen", true)
+		c.SetSensitive("token", "secret")
+		return c.String(http.StatusCreated, c.Param("id"))
+	})
+	r.Get("/", func(c *Context) error {
+		if len(c.Params) != 0 {
+			t.Errorf("expected no params, got %v", c.Params)
+		}
+		if c.IsHeaderWritten() || c.pattern != "/" {
+			t.Error("expected a fresh response and pattern")
+		}
+		return c.String(http.StatusOK, "root")
+	})
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/users/"+strconv.Itoa(i), nil))
+		if w.Code != http.StatusCreated || w.Body.String() != strconv.Itoa(i) {
+			t.Errorf("expected 201 %d, got %d %q", i, w.Code, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "root" {
+			t.Errorf("expected 200 root, got %d %q", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStaticLookupHasNilParams(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error {
+		return c.String(http.StatusOK, "id=%s", c.Param("id"))
+	}
+	r.Get("/users", handler)
+	r.Get("/users/:id", handler)
+
+	if _, params := r.tree.Lookup("GET", "/users"); params != nil {
+		t.Errorf("expected no params map for a static route, got %v", params)
+	}
+	if _, params := r.tree.Lookup("GET", "/users/5"); params["id"] != "5" {
+		t.Errorf("expected id 5, got %v", params)
+	}
+
+	// Param reads fine without a params map
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	if w.Body.String() != "id=" {
+		t.Errorf("expected an empty param, got %q", w.Body.String())
+	}
+	if (&Context{}).Param("id") != "" {
+		t.Error("expected Param to tolerate nil Params")
+	}
+}
+
+func TestMiddlewareChainIsCached(t *testing.T) {
+	r := New()
+
+	built := 0
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		built++
+		return next
+	})
+	api := r.Group("/api")
+	api.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/users", nil))
+		return w
+	}
+
+	get()
+	get()
+	if built != 1 {
+		t.Errorf("expected the chain to be built once, built %d times", built)
+	}
+
+	// Middleware added after the first request still applies
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Global", "yes")
+			return next(c)
+		}
+	})
+	api.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Group", "yes")
+			return next(c)
+		}
+	})
+
+	w := get()
+	if w.Header().Get("X-Global") != "yes" || w.Header().Get("X-Group") != "yes" {
+		t.Errorf("expected later middleware to run, got headers %v", w.Header())
+	}
+	if built != 2 {
+		t.Errorf("expected the chain to be rebuilt once, built %d times", built)
+	}
+}
+
+func TestLookupSegmentBoundaries(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/a/:x/c", handler)
+	r.Get("/files/*filepath", handler)
+	r.Get("/ab", handler)
+
+	tests := []struct {
+		path   string
+		found  bool
+		params map[string]string
+	}{
+		{"/a/b/c", true, map[string]string{"x": "b"}},
+		{"/a/b/c/", true, map[string]string{"x": "b"}},
+		{"/a//c", true, map[string]string{"x": ""}},
+		{"/a/b", false, nil},
+		{"/a/b/cd", false, nil},
+		{"/ab", true, nil},
+		{"/a", false, nil},
+		{"/files/css/site.css", true, map[string]string{"filepath": "css/site.css"}},
+		{"/files/a//b", true, map[string]string{"filepath": "a//b"}},
+	}
+
+	for _, tt := range tests {
+		node, params := r.tree.Lookup("GET", tt.path)
+		if (node != nil) != tt.found {
+			t.Errorf("%s: expected found=%v", tt.path, tt.found)
+			continue
+		}
+		for name, want := range tt.params {
+			if got, ok := params[name]; !ok || got != want {
+				t.Errorf("%s: expected %s=%q, got %v", tt.path, name, want, params)
+			}
+		}
+	}
+}
+
+func TestStaticSiblingsSharingPrefixes(t *testing.T) {
+	r := New()
+	for _, path := range []string{"/users", "/user", "/us", "/uploads", "/user-settings", "/:page", "/users/:id", "/user/profile"} {
+		path := path
+		r.Get(path, func(c *Context) error {
+			return c.String(http.StatusOK, path)
+		})
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users", "/users"},
+		{"/user", "/user"},
+		{"/us", "/us"},
+		{"/uploads", "/uploads"},
+		{"/user-settings", "/user-settings"},
+		{"/use", "/:page"},
+		{"/u", "/:page"},
+		{"/usersx", "/:page"},
+		{"/users/5", "/users/:id"},
+		{"/user/profile", "/user/profile"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Body.String() != tt.want {
+			t.Errorf("%s: expected %s, got %d %q", tt.path, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStaticBeatsParamRegisteredFirst(t *testing.T) {
+	r := New()
+	r.Get("/files/*path", func(c *Context) error { return c.String(http.StatusOK, "wildcard") })
+	r.Get("/files/:name", func(c *Context) error { return c.String(http.StatusOK, "param") })
+	r.Get("/files/zip", func(c *Context) error { return c.String(http.StatusOK, "zip") })
+	r.Get("/files/archive", func(c *Context) error { return c.String(http.StatusOK, "archive") })
+	r.Get("/files/:id(int)", func(c *Context) error { return c.String(http.StatusOK, "int") })
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/files/zip", "zip"},
+		{"/files/archive", "archive"},
+		{"/files/42", "int"},
+		{"/files/readme", "param"},
+		{"/files/a/b", "wildcard"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Body.String() != tt.want {
+			t.Errorf("%s: expected %s, got %d %q", tt.path, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Resources("/users", &TestController{}, Only(IndexAction, CreateAction, ShowAction))
+	r.Get("/health", handler)
+	r.Delete("/sessions/:id", handler)
+	r.Get("/posts/:slug?", handler, WithName("post"))
+
+	var buf bytes.Buffer
+	if err := r.PrintRoutes(&buf); err != nil {
+		t.Fatalf("PrintRoutes failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := [][]string{
+		{"METHOD", "PATTERN", "NAME"},
+		{"GET", "/health", "health_index"},
+		{"GET", "/posts/:slug?", "post"},
+		{"DELETE", "/sessions/:id", "sessions_destroy"},
+		{"GET", "/users", "users_index"},
+		{"POST", "/users", "users_create"},
+		{"GET", "/users/:id", "users_show"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), buf.String())
+	}
+	for i, fields := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("line %d: expected %v, got %q", i, fields, lines[i])
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/users", handler)
+	r.Get("/users/:id", handler)
+	r.Get("/users/:id/posts/:post_id", handler)
+	r.Post("/users", handler)
+
+	stats := r.Stats()
+	if stats.Routes["GET"] != 3 || stats.Routes["POST"] != 1 {
+		t.Errorf("expected 3 GET and 1 POST routes, got %v", stats.Routes)
+	}
+	if stats.MaxDepth != 4 {
+		t.Errorf("expected a depth of 4, got %d", stats.MaxDepth)
+	}
+	// GET: root, users, :id, posts, :post_id; POST: root, users
+	if stats.Nodes != 7 {
+		t.Errorf("expected 7 nodes, got %d", stats.Nodes)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+	r.Get("/users", handler, WithName("users"))
+	r.Post("/users", handler)
+	r.Get("/users/:id/", handler, WithName("user"))
+	r.Put("/users/:id", handler)
+	r.Delete("/users/:id", handler)
+	r.GetAll([]string{"/about", "/about-us"}, handler)
+	r.handle("PURGE", "/cache", handler, "")
+
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	unnamed := 0
+	r.Walk(func(method, pattern string, route *Route) {
+		counts[method]++
+		if route == nil {
+			unnamed++
+			return
+		}
+		names[method+" "+pattern] = route.Name
+	})
+
+	want := map[string]int{"GET": 4, "POST": 1, "PUT": 1, "DELETE": 1, "PURGE": 1}
+	for method, n := range want {
+		if counts[method] != n {
+			t.Errorf("expected %d %s routes, got %d", n, method, counts[method])
+		}
+	}
+	if names["GET /users"] != "users" || names["GET /users/:id"] != "user" {
+		t.Errorf("expected named routes to carry their names, got %v", names)
+	}
+	// The alias /about-us has no name
+	if unnamed != 1 {
+		t.Errorf("expected 1 unnamed route, got %d", unnamed)
+	}
+}
+
+func TestBraceParams(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error {
+		return c.String(200, c.Params["id"]+"|"+c.Params["path"])
+	}
+	r.Get("/users/{id}", handler)
+	r.Get("/users/:id/files/{*path}", handler, WithName("user_file"))
+
+	tests := []struct {
+		path, body string
+	}{
+		{"/users/5", "5|"},
+		{"/users/5/files/docs/a.txt", "5|docs/a.txt"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != tt.body {
+			t.Errorf("GET %s: expected 200 %q, got %d %q", tt.path, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	// Both styles register the same route
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering /users/:id after /users/{id} to panic")
+		}
+	}()
+
+	routes := r.NamedRoutes()
+	if route := routes["user_file"]; route == nil || route.Pattern != "/users/:id/files/*path" {
+		t.Errorf("expected the pattern in :param form, got %+v", route)
+	}
+	if route := routes["users_show"]; route == nil || route.Pattern != "/users/:id" {
+		t.Errorf("expected a generated users_show name, got %+v", route)
+	}
+	if url, err := r.URLFor("users_show", "7"); err != nil || url != "/users/7" {
+		t.Errorf("expected /users/7, got %q (%v)", url, err)
+	}
+	r.Get("/users/:id", handler)
+}
+
+func TestAny(t *testing.T) {
+	r := New()
+	var methods []string
+	r.Any("/webhook", func(c *Context) error {
+		methods = append(methods, c.Request.Method)
+		return c.String(200, "received")
+	}, WithName("webhook"))
+
+	for _, method := range []string{"GET", "POST", "DELETE", "PATCH"} {
+		req := httptest.NewRequest(method, "/webhook", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != "received" {
+			t.Errorf("%s: expected 200 received, got %d %q", method, w.Code, w.Body.String())
+		}
+	}
+	if strings.Join(methods, ",") != "GET,POST,DELETE,PATCH" {
+		t.Errorf("expected one handler for every method, got %v", methods)
+	}
+
+	if route := r.NamedRoutes()["webhook"]; route == nil || route.Method != "GET" {
+		t.Errorf("expected the GET route to be named webhook, got %+v", route)
+	}
+}
+
+func TestAnyCatchAll(t *testing.T) {
+	r := New()
+	r.Get("/users", func(c *Context) error { return c.String(200, "users") })
+	api := r.Group("/api")
+	api.Any("/*path", func(c *Context) error {
+		return c.String(200, "fallback "+c.Param("path"))
+	})
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{"GET", "/users", "users"},
+		{"PUT", "/api/anything/here", "fallback anything/here"},
+		{"OPTIONS", "/api/x", "fallback x"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	r := New()
+	r.Match([]string{"GET", "POST"}, "/contact", func(c *Context) error {
+		return c.String(200, c.Request.Method)
+	})
+	api := r.Group("/api")
+	api.Match([]string{"PUT"}, "/contact", func(c *Context) error {
+		return c.String(200, "api")
+	})
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "/contact", 200},
+		{"POST", "/contact", 200},
+		{"PUT", "/contact", 405},
+		{"DELETE", "/contact", 405},
+		{"PUT", "/api/contact", 200},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.status, w.Code)
+		}
+	}
+
+	if route := r.NamedRoutes()["contact_index"]; route == nil || route.Method != "GET" {
+		t.Errorf("expected only the GET route to be named, got %+v", r.NamedRoutes())
+	}
+	if _, ok := r.NamedRoutes()["contact_create"]; ok {
+		t.Error("expected the POST route to be unnamed")
+	}
+}
+
+func TestMatchInvalidMethods(t *testing.T) {
+	for _, methods := range [][]string{{"GET", "FETCH"}, {"get"}, nil} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %v to panic", methods)
+				}
+			}()
+			New().Match(methods, "/contact", func(c *Context) error { return nil })
+		}()
+	}
+}
+
+func TestTryRegistration(t *testing.T) {
+	handler := func(c *Context) error { return c.String(200, "ok") }
+
+	tests := []struct {
+		name string
+		try  func(r *Router) error
+		want string
+	}{
+		{"missing slash", func(r *Router) error { return r.TryGet("users", handler) }, "must begin with '/'"},
+		{"duplicate param", func(r *Router) error { return r.TryPost("/users/:id/posts/:id", handler) }, `duplicate parameter "id"`},
+		{"wildcard not last", func(r *Router) error { return r.TryPut("/files/*path/edit", handler) }, "must be the last segment"},
+		{"optional not last", func(r *Router) error { return r.TryPatch("/posts/:id?/edit", handler) }, "must be the last segment"},
+		{"unknown constraint", func(r *Router) error { return r.TryDelete("/users/:id(number)", handler) }, "unknown constraint"},
+		{"duplicate route", func(r *Router) error { return r.TryGet("/existing", handler) }, "duplicate route"},
+		{"name taken", func(r *Router) error { return r.TryHead("/other", handler, WithName("existing")) }, `route name "existing" is already used`},
+		{"invalid host", func(r *Router) error { return r.TryOptions("/", handler, WithHost("api.*.com")) }, "invalid host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Get("/existing", handler, WithName("existing"))
+
+			err := tt.try(r)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTryRegistrationLeavesNothingBehind(t *testing.T) {
+	r := New()
+	handler := func(c *Context) error { return c.String(200, "ok") }
+	r.Get("/users", handler, WithName("users"))
+
+	if err := r.TryGet("/people", handler, WithName("users")); err == nil {
+		t.Fatal("expected the taken name to fail")
+	}
+	if w := r.TestRequest("GET", "/people", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected the failed route not to be registered, got %d", w.Code)
+	}
+
+	if err := r.TryGet("/people", handler, WithName("people")); err != nil {
+		t.Fatalf("expected a valid route to register, got %v", err)
+	}
+	if w := r.TestRequest("GET", "/people", nil); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+
+	if err := r.TryGet("/posts/:id(number)?", handler); err == nil || !strings.Contains(err.Error(), "unknown constraint") {
+		t.Fatalf("expected an unknown constraint error, got %v", err)
+	}
+	if w := r.TestRequest("GET", "/posts", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected the invalid optional route not to be registered, got %d", w.Code)
+	}
+
+	r.Get("/articles/:id", handler)
+	other := func(c *Context) error { return c.String(200, "other") }
+	if err := r.TryGet("/articles/:id?", other); err == nil || !strings.Contains(err.Error(), "duplicate route") {
+		t.Fatalf("expected a duplicate route error, got %v", err)
+	}
+	if w := r.TestRequest("GET", "/articles", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected the duplicate optional route not to be registered, got %d %q", w.Code, w.Body.String())
+	}
+
+	r.Freeze()
+	if err := r.TryPost("/people", handler); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Errorf("expected a frozen router error, got %v", err)
+	}
+}
+
+func TestRegistrationStillPanics(t *testing.T) {
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "must begin with '/'") {
+			t.Errorf("expected Get to panic with the registration error, got %q", msg)
+		}
+	}()
+	New().Get("users", func(c *Context) error { return nil })
+}
+
+// Benchmarks
+
+func BenchmarkStaticRoutes(b *testing.B) {
+	r := New()
+	r.Get("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkParameterRoutes(b *testing.B) {
+	r := New()
+	r.Get("/users/:id", func(c *Context) error {
+		id := c.Param("id")
+		return c.String(http.StatusOK, id)
+	})
+
+	req := httptest.NewRequest("GET", "/users/123", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkWildcardRoutes(b *testing.B) {
+	r := New()
+	r.Get("/files/*filepath", func(c *Context) error {
+		path := c.Param("filepath")
+		return c.String(http.StatusOK, path)
+	})
+
+	req := httptest.NewRequest("GET", "/files/some/deep/path/file.txt", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkComplexRouting(b *testing.B) {
+	r := New()
+
+	// Multiple routes to simulate realistic routing
+	r.Get("/", func(c *Context) error { return c.String(http.StatusOK, "home") })
+	r.Get("/about", func(c *Context) error { return c.String(http.StatusOK, "about") })
+	r.Get("/contact", func(c *Context) error { return c.String(http.StatusOK, "contact") })
+	r.Get("/users", func(c *Context) error { return c.String(http.StatusOK, "users") })
+	r.Get("/users/:id", func(c *Context) error { return c.String(http.StatusOK, "user") })
+	r.Get("/users/:id/posts", func(c *Context) error { return c.String(http.StatusOK, "posts") })
+	r.Get("/users/:id/posts/:post_id", func(c *Context) error { return c.String(http.StatusOK, "post") })
+	r.Get("/files/*filepath", func(c *Context) error { return c.String(http.StatusOK, "file") })
+
+	req := httptest.NewRequest("GET", "/users/42/posts/123", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkTreeFind100Routes(b *testing.B) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	// 50 top-level resources sharing the "resource" prefix, each with a
+	// member route
+	for i := 0; i < 50; i++ {
+		r.handle("GET", fmt.Sprintf("/resource%d", i), handler, "")
+		r.handle("GET", fmt.Sprintf("/resource%d/:id", i), handler, "")
+	}
+
+	paths := []string{"/resource0", "/resource49/7", "/resource25", "/resource31/12", "/missing"}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		r.tree.Find("GET", paths[i%len(paths)])
+	}
+}
+
+func BenchmarkMiddlewareChain(b *testing.B) {
+	r := New()
+
+	// Add multiple middleware
+	middleware1 := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			return next(c)
+		}
+	}
+	middleware2 := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			return next(c)
+		}
+	}
+	middleware3 := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			return next(c)
+		}
+	}
+
+	r.Use(middleware1, middleware2, middleware3)
+
+	r.Get("/test", func(c *Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkDeepMiddlewareChain(b *testing.B) {
+	r := New()
+
+	passthrough := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			return next(c)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		r.Use(passthrough)
+	}
+	api := r.Group("/api", passthrough, passthrough, passthrough)
+	api.Get("/test", func(c *Context) error {
+		return c.String(http.StatusOK, "test")
+	}, WithMiddleware(passthrough, passthrough))
+
+	req := httptest.NewRequest("GET", "/api/test", nil)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkTreeFindParams(b *testing.B) {
+	r := New()
+	handler := func(c *Context) error { return nil }
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		r.handle(method, "/users", handler, "")
+		r.handle(method, "/users/:id", handler, "")
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		r.tree.Find("DELETE", "/users/123")
+	}
+}
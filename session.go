@@ -0,0 +1,330 @@
+package router
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Session holds values that are kept across a client's requests. Sessions
+// are loaded and saved by the Sessions middleware, and handlers reach the
+// current one through Context.Session.
+type Session struct {
+	// Name is the name the session is stored under, such as its cookie name
+	Name string
+
+	// Values are the session's values. Use Set and Delete rather than
+	// changing the map directly, so the session is known to need saving.
+	Values map[string]interface{}
+
+	// IsNew is true if the client didn't have the session yet
+	IsNew bool
+
+	modified bool // changed since it was loaded
+}
+
+// NewSession returns an empty session, for Store implementations
+func NewSession(name string) *Session {
+	return &Session{Name: name, Values: make(map[string]interface{}), IsNew: true}
+}
+
+// Get returns a session value
+func (s *Session) Get(key string) (interface{}, bool) {
+	val, ok := s.Values[key]
+	return val, ok
+}
+
+// GetString returns a session value if it is a string
+func (s *Session) GetString(key string) (string, bool) {
+	val, ok := s.Values[key].(string)
+	return val, ok
+}
+
+// GetInt returns a session value if it is an int. Stores that encode
+// values as JSON, like CookieStore, load numbers as float64; whole numbers
+// are returned as ints too.
+func (s *Session) GetInt(key string) (int, bool) {
+	switch val := s.Values[key].(type) {
+	case int:
+		return val, true
+	case float64:
+		if val == float64(int(val)) {
+			return int(val), true
+		}
+	}
+	return 0, false
+}
+
+// GetBool returns a session value if it is a bool
+func (s *Session) GetBool(key string) (bool, bool) {
+	val, ok := s.Values[key].(bool)
+	return val, ok
+}
+
+// Set stores a session value
+func (s *Session) Set(key string, value interface{}) {
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes a session value
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// Clear removes every session value, as when logging out
+func (s *Session) Clear() {
+	clear(s.Values)
+	s.modified = true
+}
+
+// Store loads and saves sessions for the Sessions middleware
+type Store interface {
+	// Get returns the request's session with the given name, or a new
+	// session (see NewSession) if the request doesn't have one
+	Get(r *http.Request, name string) (*Session, error)
+
+	// Save persists a session, setting any cookie it needs on w. It is
+	// called before the response headers are written.
+	Save(w http.ResponseWriter, r *http.Request, s *Session) error
+}
+
+// SessionOption is a functional option for configuring Sessions
+type SessionOption func(*sessionConfig)
+
+// sessionConfig holds the configuration for Sessions
+type sessionConfig struct {
+	name string
+}
+
+// WithSessionName sets the name sessions are stored under, which is the
+// cookie name for CookieStore. The default is "session".
+func WithSessionName(name string) SessionOption {
+	return func(cfg *sessionConfig) {
+		cfg.name = name
+	}
+}
+
+// sessionStoreKey is the Context store key for the Session
+const sessionStoreKey = "router.session"
+
+// Sessions returns middleware that loads the request's session from store
+// before the handler runs and saves it, if it changed, once the handler
+// has finished with it:
+//
+//	r.Use(router.Sessions(router.NewCookieStore(key)))
+//
+//	r.Post("/login", func(c *router.Context) error {
+//	    c.Session().Set("user_id", user.ID)
+//	    return c.Redirect(http.StatusSeeOther, "/")
+//	})
+//
+// The session is saved just before the response headers are written, so
+// that its cookie can be sent, or after the handler returns if it wrote
+// nothing. Changes made after the response has started are lost.
+func Sessions(store Store, opts ...SessionOption) MiddlewareFunc {
+	cfg := &sessionConfig{name: "session"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			session, err := store.Get(c.Request, cfg.name)
+			if err != nil {
+				return err
+			}
+			c.Set(sessionStoreKey, session)
+
+			sw := &sessionWriter{ResponseWriter: c.Writer.ResponseWriter, store: store, req: c.Request, session: session}
+			c.Writer.ResponseWriter = sw
+			defer func() {
+				c.Writer.ResponseWriter = sw.ResponseWriter
+			}()
+
+			err = next(c)
+			sw.save()
+			if err == nil {
+				err = sw.err
+			}
+			return err
+		}
+	}
+}
+
+// Session returns the request's session, or nil if Sessions isn't in use
+func (c *Context) Session() *Session {
+	session, _ := c.store[sessionStoreKey].(*Session)
+	return session
+}
+
+// sessionWriter saves the session before the response headers are written
+type sessionWriter struct {
+	http.ResponseWriter
+	store   Store
+	req     *http.Request
+	session *Session
+	saved   bool
+	err     error // from saving
+}
+
+// save saves the session, once, if it was modified
+func (w *sessionWriter) save() {
+	if w.saved {
+		return
+	}
+	w.saved = true
+	if w.session.modified {
+		w.err = w.store.Save(w.ResponseWriter, w.req, w.session)
+	}
+}
+
+// WriteHeader saves the session while its cookie can still be set
+func (w *sessionWriter) WriteHeader(code int) {
+	w.save()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write saves the session if the headers haven't been written yet
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.save()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer
+func (w *sessionWriter) Flush() {
+	w.save()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes through to the underlying writer
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// maxCookieSize is the largest cookie browsers are guaranteed to keep
+const maxCookieSize = 4096
+
+// CookieStore is a Store that keeps the whole session in a cookie. The
+// values are encoded as JSON and signed, like SetSignedCookie, so the
+// client can read them but not change them; don't store secrets in them.
+// Sessions must fit in a 4 KB cookie.
+//
+// Sessions with an invalid signature, for example after the key changed,
+// are replaced with new sessions. The time a session was saved is signed
+// along with it, and with a MaxAge set, sessions saved longer ago than
+// that are replaced too, so a copied cookie stops working even if the
+// client keeps it.
+type CookieStore struct {
+	// Key signs the cookies. Use a random key of at least 32 bytes.
+	Key []byte
+
+	// Path, Domain, MaxAge, Secure and SameSite are set on the cookie,
+	// which is always HttpOnly. A zero MaxAge makes it a browser session
+	// cookie, which the store doesn't expire itself.
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// NewCookieStore returns a CookieStore signing with key, for cookies on
+// path "/" with SameSite=Lax
+func NewCookieStore(key []byte) *CookieStore {
+	return &CookieStore{Key: key, Path: "/", SameSite: http.SameSiteLaxMode}
+}
+
+// Get implements Store
+func (s *CookieStore) Get(r *http.Request, name string) (*Session, error) {
+	session := NewSession(name)
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	value, ok := verifyCookieValue(name, cookie.Value, s.Key)
+	if !ok {
+		return session, nil
+	}
+
+	// The value is the encoded session and the time it was saved
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return session, nil
+	}
+	saved, err := strconv.ParseInt(value[i+1:], 10, 64)
+	if err != nil || s.expired(saved) {
+		return session, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value[:i])
+	if err != nil {
+		return session, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return session, nil
+	}
+	if values != nil {
+		session.Values = values
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+// Save implements Store. A session without values deletes its cookie.
+func (s *CookieStore) Save(w http.ResponseWriter, r *http.Request, session *Session) error {
+	cookie := &http.Cookie{
+		Name:     session.Name,
+		Path:     s.Path,
+		Domain:   s.Domain,
+		MaxAge:   s.MaxAge,
+		Secure:   s.Secure,
+		HttpOnly: true,
+		SameSite: s.SameSite,
+	}
+
+	if len(session.Values) == 0 {
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+		return nil
+	}
+
+	data, err := json.Marshal(session.Values)
+	if err != nil {
+		return fmt.Errorf("encoding session %q: %w", session.Name, err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(data) + "." + strconv.FormatInt(time.Now().Unix(), 10)
+	cookie.Value = signCookieValue(session.Name, value, s.Key)
+	if size := len(cookie.String()); size > maxCookieSize {
+		return fmt.Errorf("session %q is too large for a cookie (%d bytes)", session.Name, size)
+	}
+
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// expired reports whether a session saved at the given Unix time is older
+// than MaxAge
+func (s *CookieStore) expired(saved int64) bool {
+	return s.MaxAge > 0 && time.Since(time.Unix(saved, 0)) > time.Duration(s.MaxAge)*time.Second
+}
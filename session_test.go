@@ -0,0 +1,194 @@
+package router
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessions(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	r := New()
+	r.Use(Sessions(NewCookieStore(key)))
+	r.Post("/login", func(c *Context) error {
+		c.Session().Set("user_id", 42)
+		c.Session().Set("name", "ada")
+		return c.String(http.StatusOK, "logged in")
+	})
+	r.Get("/me", func(c *Context) error {
+		id, _ := c.Session().GetInt("user_id")
+		name, _ := c.Session().GetString("name")
+		return c.String(http.StatusOK, strconv.Itoa(id)+" "+name)
+	})
+	r.Post("/logout", func(c *Context) error {
+		c.Session().Clear()
+		return nil
+	})
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	send := func(method, path string) string {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	if got := send("GET", "/me"); got != "0 " {
+		t.Errorf("expected an empty session, got %q", got)
+	}
+	send("POST", "/login")
+	if got := send("GET", "/me"); got != "42 ada" {
+		t.Errorf("expected the session to be kept, got %q", got)
+	}
+	send("POST", "/logout")
+	if got := send("GET", "/me"); got != "0 " {
+		t.Errorf("expected the session to be cleared, got %q", got)
+	}
+}
+
+func TestSessionsUnchangedSessionIsNotSaved(t *testing.T) {
+	r := New()
+	r.Use(Sessions(NewCookieStore([]byte("key"))))
+	r.Get("/", func(c *Context) error {
+		c.Session().Get("user_id")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if cookie := w.Header().Get("Set-Cookie"); cookie != "" {
+		t.Errorf("expected no cookie, got %q", cookie)
+	}
+}
+
+func TestSessionsSaveError(t *testing.T) {
+	r := New()
+	r.Use(Sessions(NewCookieStore([]byte("key"))))
+	r.Get("/", func(c *Context) error {
+		c.Session().Set("data", strings.Repeat("x", 5000))
+		return nil
+	})
+	r.ErrorHandler = func(c *Context, err error) {
+		c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "too large") {
+		t.Errorf("expected the save error, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestCookieStoreRejectsTamperedSession(t *testing.T) {
+	store := NewCookieStore([]byte("key"))
+	session := NewSession("session")
+	session.Set("admin", false)
+
+	w := httptest.NewRecorder()
+	if err := store.Save(w, nil, session); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	cookie := w.Result().Cookies()[0]
+	if !cookie.HttpOnly || cookie.Path != "/" {
+		t.Errorf("unexpected cookie attributes: %v", cookie)
+	}
+
+	cookie.Value = "eyJhZG1pbiI6dHJ1ZX0" + cookie.Value[strings.LastIndexByte(cookie.Value, '.'):]
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+
+	loaded, err := store.Get(req, "session")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !loaded.IsNew || len(loaded.Values) != 0 {
+		t.Errorf("expected a new session, got %+v", loaded)
+	}
+}
+
+func TestCookieStoreRejectsExpiredSession(t *testing.T) {
+	store := NewCookieStore([]byte("key"))
+	store.MaxAge = 3600
+
+	// A session saved two hours ago, as a client holding on to it would send
+	saved := strconv.FormatInt(time.Now().Add(-2*time.Hour).Unix(), 10)
+	value := base64.RawURLEncoding.EncodeToString([]byte(`{"user_id":42}`)) + "." + saved
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: signCookieValue("session", value, store.Key)})
+
+	loaded, err := store.Get(req, "session")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !loaded.IsNew || len(loaded.Values) != 0 {
+		t.Errorf("expected the expired session to be replaced, got %+v", loaded)
+	}
+
+	// A fresh one is kept
+	w := httptest.NewRecorder()
+	session := NewSession("session")
+	session.Set("user_id", 42)
+	if err := store.Save(w, nil, session); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(w.Result().Cookies()[0])
+	if loaded, _ := store.Get(req, "session"); loaded.IsNew {
+		t.Error("expected the fresh session to be loaded")
+	}
+}
+
+func TestContextSessionWithoutMiddleware(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if c.Session() != nil {
+		t.Error("expected no session")
+	}
+}
+
+// failingStore is a Store whose Get fails
+type failingStore struct{}
+
+func (failingStore) Get(r *http.Request, name string) (*Session, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStore) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	return nil
+}
+
+func TestSessionsStoreError(t *testing.T) {
+	called := false
+	r := New()
+	r.Use(Sessions(failingStore{}))
+	r.Get("/", func(c *Context) error {
+		called = true
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if called || w.Code != http.StatusInternalServerError {
+		t.Errorf("expected a 500 without calling the handler, got %d (called %v)", w.Code, called)
+	}
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StaticOption is a functional option for configuring Static
+type StaticOption func(*staticConfig)
+
+// staticConfig holds the configuration for a static file route
+type staticConfig struct {
+	cacheControl string
+}
+
+// WithCacheControl sets a "Cache-Control: max-age" header on static file
+// responses, including 304 Not Modified responses
+func WithCacheControl(maxAge time.Duration) StaticOption {
+	return func(cfg *staticConfig) {
+		cfg.cacheControl = fmt.Sprintf("max-age=%d", int(maxAge.Seconds()))
+	}
+}
+
+// Static serves the files under the root directory at prefix, for GET and
+// HEAD requests. Content types are set from the file extension. Responses
+// carry Last-Modified, and requests with a matching If-Modified-Since get
+// 304 Not Modified, as with http.ServeFile. A directory is served by its
+// index.html; directories are never listed. Missing files are passed to
+// the router's NotFound handler.
+//
+// Example:
+//
+//	r.Static("/assets", "./public", WithCacheControl(24*time.Hour))
+//	// GET /assets/css/app.css -> ./public/css/app.css
+//
+// Paths containing ".." segments are rejected as not found, and the rest
+// are cleaned with Context.WildcardPath, so requests can't escape root.
+func (r *Router) Static(prefix, root string, opts ...StaticOption) {
+	r.static(nil, prefix, root, opts)
+}
+
+// Static serves the files under the root directory at the group's prefix
+// plus prefix, behind the group's middleware.
+// See Router.Static() for details.
+func (g *Group) Static(prefix, root string, opts ...StaticOption) {
+	if g.disabled {
+		return
+	}
+
+	g.router.static(g, g.prefix+prefix, root, opts)
+}
+
+// static registers the routes serving root at prefix
+func (r *Router) static(g *Group, prefix, root string, opts []StaticOption) {
+	cfg := &staticConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	handler := staticHandler(root, cfg)
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	// Static routes aren't named; their patterns make no useful helpers.
+	// The prefix itself serves root's index.html.
+	for _, path := range []string{prefix + "/", prefix + "/*filepath"} {
+		r.addRoute(g, "GET", path, handler, &routeConfig{})
+		r.addRoute(g, "HEAD", path, handler, &routeConfig{})
+	}
+}
+
+// staticHandler returns the handler serving files from root
+func staticHandler(root string, cfg *staticConfig) HandlerFunc {
+	return func(c *Context) error {
+		if hasDotDot(c.Param("filepath")) {
+			return c.router.notFoundHandler(c.Request.URL.Path)(c)
+		}
+		name := filepath.Join(root, filepath.FromSlash(c.WildcardPath("filepath")))
+
+		f, err := os.Open(name)
+		if err != nil {
+			return c.router.notFoundHandler(c.Request.URL.Path)(c)
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			f.Close()
+			if f, err = os.Open(filepath.Join(name, "index.html")); err != nil {
+				return c.router.notFoundHandler(c.Request.URL.Path)(c)
+			}
+			defer f.Close()
+
+			if info, err = f.Stat(); err != nil || info.IsDir() {
+				return c.router.notFoundHandler(c.Request.URL.Path)(c)
+			}
+		}
+
+		// Set before serving so 304 responses carry it too
+		if cfg.cacheControl != "" {
+			c.SetHeader("Cache-Control", cfg.cacheControl)
+		}
+
+		http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+		return nil
+	}
+}
+
+// hasDotDot reports whether a requested path has a ".." segment
+func hasDotDot(p string) bool {
+	for _, segment := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
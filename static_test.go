@@ -0,0 +1,129 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newStaticRoot(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "css"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "css", "app.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "index.html"), []byte("<h1>home</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestStatic(t *testing.T) {
+	r := New()
+	r.Static("/assets", newStaticRoot(t))
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+		body     string
+	}{
+		{"GET", "/assets/css/app.css", http.StatusOK, "body{}"},
+		{"HEAD", "/assets/css/app.css", http.StatusOK, ""},
+		{"GET", "/assets/", http.StatusOK, "<h1>home</h1>"},
+		{"GET", "/assets/missing.css", http.StatusNotFound, ""},
+		{"GET", "/assets/css", http.StatusNotFound, ""},
+		{"GET", "/assets/../static_test.go", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStaticConditionalGet(t *testing.T) {
+	r := New()
+	r.Static("/assets", newStaticRoot(t), WithCacheControl(time.Hour))
+
+	req := httptest.NewRequest("GET", "/assets/css/app.css", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	lastModified := w.Header().Get("Last-Modified")
+	if lastModified == "" {
+		t.Fatal("expected a Last-Modified header")
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "max-age=3600" {
+		t.Errorf("expected Cache-Control max-age=3600, got %q", cc)
+	}
+
+	req = httptest.NewRequest("GET", "/assets/css/app.css", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", w.Code)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "max-age=3600" {
+		t.Errorf("expected Cache-Control on the 304 response, got %q", cc)
+	}
+}
+
+func TestGroupStatic(t *testing.T) {
+	r := New()
+	r.NotFound = func(c *Context) error {
+		return c.String(http.StatusNotFound, "custom not found")
+	}
+
+	var hits int
+	web := r.Group("/web", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			hits++
+			return next(c)
+		}
+	})
+	web.Static("/assets", newStaticRoot(t))
+
+	req := httptest.NewRequest("GET", "/web/assets/css/app.css", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" {
+		t.Errorf("expected 200 body{}, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/css; charset=utf-8" {
+		t.Errorf("expected text/css content type, got %q", got)
+	}
+	if hits != 1 {
+		t.Errorf("expected group middleware to run once, ran %d times", hits)
+	}
+
+	for _, path := range []string{"/web/assets/missing.css", "/web/assets/%2e%2e/%2e%2e/static_test.go"} {
+		req = httptest.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound || w.Body.String() != "custom not found" {
+			t.Errorf("%s: expected the NotFound handler, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"io"
+	"net/http/httptest"
+)
+
+// TestRequest sends a request through the router and returns the recorded
+// response, for tests that would otherwise build the request and recorder
+// themselves:
+//
+//	r := router.New()
+//	r.Get("/users/:id", showUser)
+//
+//	rec := r.TestRequest("GET", "/users/123", nil)
+//	if rec.Code != http.StatusOK {
+//	    t.Errorf("expected 200, got %d", rec.Code)
+//	}
+//
+// The request is built with httptest.NewRequest, so path may include a
+// query string, and a nil body sends none. For requests that need headers,
+// build them with httptest.NewRequest and call ServeHTTP directly.
+func (r *Router) TestRequest(method, path string, body io.Reader) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, body))
+	return rec
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTestRequest(t *testing.T) {
+	r := New()
+	r.Get("/users/:id", func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+	r.Post("/users", func(c *Context) error {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return err
+		}
+		return c.String(http.StatusCreated, req.Name)
+	})
+
+	rec := r.TestRequest("GET", "/users/123", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"id":"123"}` {
+		t.Errorf(`expected {"id":"123"}, got %s`, body)
+	}
+
+	rec = r.TestRequest("POST", "/users", strings.NewReader(`{"name":"ada"}`))
+	if rec.Code != http.StatusCreated || rec.Body.String() != "ada" {
+		t.Errorf("expected 201 ada, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = r.TestRequest("GET", "/missing?page=2", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func ExampleRouter_TestRequest() {
+	r := New()
+	r.Get("/users/:id", func(c *Context) error {
+		return c.String(http.StatusOK, "user "+c.Param("id"))
+	})
+
+	// Instead of building a request and a recorder and calling ServeHTTP:
+	//
+	//	req := httptest.NewRequest("GET", "/users/123", nil)
+	//	rec := httptest.NewRecorder()
+	//	r.ServeHTTP(rec, req)
+	rec := r.TestRequest("GET", "/users/123", nil)
+	fmt.Println(rec.Code, rec.Body.String())
+
+	rec = r.TestRequest("GET", "/missing", nil)
+	fmt.Println(rec.Code)
+
+	// Output:
+	// 200 user 123
+	// 404
+}
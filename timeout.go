@@ -0,0 +1,118 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// timeoutHandler wraps h so that it fails with a 503 StatusError if it
+// doesn't return within d (see WithTimeout)
+func timeoutHandler(h HandlerFunc, d time.Duration) HandlerFunc {
+	return func(c *Context) error {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		// Run the handler on a copy of the Context writing into a buffer,
+		// so nothing reaches the client if it times out
+		tw := &timeoutWriter{header: make(http.Header)}
+		tc := *c
+		tc.Writer = &responseWriter{ResponseWriter: tw, status: c.Writer.status}
+		tc.Request = c.Request.WithContext(ctx)
+
+		done := make(chan error, 1)
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			done <- h(&tc)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case err := <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+
+			dst := c.Writer.Header()
+			for k, v := range tw.header {
+				dst[k] = v
+			}
+			if tw.wroteHeader {
+				c.Writer.WriteHeader(tw.status)
+			} else if !c.Writer.wroteHeader {
+				// Keep a status the handler set with SetStatus
+				c.Writer.status = tc.Writer.status
+			}
+			if tw.buf.Len() > 0 {
+				c.Writer.Write(tw.buf.Bytes())
+			}
+			return err
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+
+			// The handler still holds the copy, which shares c's maps
+			c.retained = true
+
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The client went away; there's no one to respond to
+				return ctx.Err()
+			}
+			return &StatusError{
+				Code:    http.StatusServiceUnavailable,
+				Message: http.StatusText(http.StatusServiceUnavailable),
+				Err:     ctx.Err(),
+			}
+		}
+	}
+}
+
+// timeoutWriter buffers a response until the handler returns. Writes after
+// the timeout fail with http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the buffered response headers
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader records the status code
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = code
+	tw.wroteHeader = true
+}
+
+// Write buffers the response body
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.status = http.StatusOK
+		tw.wroteHeader = true
+	}
+	return tw.buf.Write(b)
+}
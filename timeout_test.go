@@ -0,0 +1,81 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	r := New()
+
+	cancelled := make(chan struct{})
+	r.Get("/slow", func(c *Context) error {
+		select {
+		case <-time.After(time.Second):
+		case <-c.Request.Context().Done():
+			close(cancelled)
+		}
+		return c.String(http.StatusOK, "too late")
+	}, WithTimeout(20*time.Millisecond))
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the handler's request context to be cancelled")
+	}
+}
+
+func TestWithTimeoutFastHandler(t *testing.T) {
+	r := New()
+
+	middlewareCalled := false
+	mw := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			middlewareCalled = true
+			return next(c)
+		}
+	}
+
+	r.Post("/fast", func(c *Context) error {
+		c.SetHeader("X-Handler", "yes")
+		return c.String(http.StatusCreated, "done")
+	}, WithTimeout(time.Second), WithMiddleware(mw))
+
+	req := httptest.NewRequest("POST", "/fast", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || w.Body.String() != "done" {
+		t.Errorf("expected the buffered response, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Handler") != "yes" {
+		t.Error("expected handler headers to be copied")
+	}
+	if !middlewareCalled {
+		t.Error("expected route middleware to run with a timeout")
+	}
+}
+
+func TestWithTimeoutSetStatus(t *testing.T) {
+	r := New()
+	r.Post("/jobs", func(c *Context) error {
+		c.SetStatus(http.StatusAccepted)
+		return nil
+	}, WithTimeout(time.Second))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/jobs", nil))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", w.Code)
+	}
+}
@@ -0,0 +1,280 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SpanContext identifies the span of a request within a distributed trace,
+// following the W3C Trace Context specification (https://www.w3.org/TR/trace-context/).
+type SpanContext struct {
+	TraceID    string // 32 lowercase hex characters, shared by the whole trace
+	SpanID     string // 16 lowercase hex characters, identifying this request's span
+	ParentID   string // span ID of the caller, or "" if the trace started here
+	Flags      byte   // trace-flags; FlagSampled marks the trace as sampled
+	TraceState string // vendor-specific tracestate list, propagated unchanged
+}
+
+// FlagSampled is the sampled bit of SpanContext.Flags
+const FlagSampled byte = 0x01
+
+// Sampled reports whether the sampled flag is set
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Propagator reads and writes span contexts from HTTP headers.
+// The default is W3CPropagator; implement Propagator to bridge to another
+// tracing system (such as an OpenTelemetry TextMapPropagator) without the
+// router depending on it.
+type Propagator interface {
+	// Extract returns the caller's span context, or false if the headers
+	// don't carry a valid one
+	Extract(h http.Header) (SpanContext, bool)
+
+	// Inject writes a span context into headers
+	Inject(h http.Header, sc SpanContext)
+}
+
+// IDGenerator creates trace and span IDs for Tracing.
+// The default generates random IDs with crypto/rand.
+type IDGenerator interface {
+	TraceID() string // 32 lowercase hex characters, not all zero
+	SpanID() string  // 16 lowercase hex characters, not all zero
+}
+
+// TracingOption is a functional option for configuring Tracing
+type TracingOption func(*tracingConfig)
+
+// tracingConfig holds the configuration for Tracing
+type tracingConfig struct {
+	propagator Propagator
+	ids        IDGenerator
+}
+
+// WithPropagator sets the Propagator used to read and write trace headers
+func WithPropagator(p Propagator) TracingOption {
+	return func(cfg *tracingConfig) {
+		cfg.propagator = p
+	}
+}
+
+// WithIDGenerator sets the IDGenerator used for new trace and span IDs
+func WithIDGenerator(ids IDGenerator) TracingOption {
+	return func(cfg *tracingConfig) {
+		cfg.ids = ids
+	}
+}
+
+// spanContextKey is the request context key for the SpanContext
+type spanContextKey struct{}
+
+// spanContextStoreKey is the Context store key for the SpanContext
+const spanContextStoreKey = "router.span_context"
+
+// Tracing returns middleware that propagates W3C trace context.
+//
+// For each request it extracts the caller's traceparent and tracestate
+// headers, starts a new span in the same trace (or a new trace if the
+// headers are absent or invalid), and echoes the new span's traceparent
+// and tracestate on the response.
+//
+// The span is available to handlers through c.TraceID() and c.SpanID(),
+// and to code that only has the request context through SpanContextFromContext:
+//
+//	r.Use(router.Tracing())
+//
+//	r.Get("/orders/:id", func(c *router.Context) error {
+//	    log.Printf("trace=%s span=%s", c.TraceID(), c.SpanID())
+//	    return fetchOrder(c.Request.Context(), c.Param("id"))
+//	})
+func Tracing(opts ...TracingOption) MiddlewareFunc {
+	cfg := &tracingConfig{
+		propagator: W3CPropagator{},
+		ids:        randomIDs{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			sc, ok := cfg.propagator.Extract(c.Request.Header)
+			if ok {
+				sc.ParentID = sc.SpanID
+			} else {
+				sc = SpanContext{TraceID: cfg.ids.TraceID(), Flags: FlagSampled}
+			}
+			sc.SpanID = cfg.ids.SpanID()
+
+			c.Set(spanContextStoreKey, sc)
+			c.SetRequest(c.Request.WithContext(ContextWithSpanContext(c.Request.Context(), sc)))
+			cfg.propagator.Inject(c.Writer.Header(), sc)
+
+			return next(c)
+		}
+	}
+}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the SpanContext stored in ctx by Tracing
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// SpanContext returns the request's span context, set by the Tracing middleware
+func (c *Context) SpanContext() (SpanContext, bool) {
+	sc, ok := c.store[spanContextStoreKey].(SpanContext)
+	return sc, ok
+}
+
+// TraceID returns the request's trace ID, or "" if Tracing isn't in use
+func (c *Context) TraceID() string {
+	sc, _ := c.SpanContext()
+	return sc.TraceID
+}
+
+// SpanID returns the request's span ID, or "" if Tracing isn't in use
+func (c *Context) SpanID() string {
+	sc, _ := c.SpanContext()
+	return sc.SpanID
+}
+
+// W3CPropagator propagates span contexts using the W3C traceparent and
+// tracestate headers.
+type W3CPropagator struct{}
+
+// Extract parses the traceparent and tracestate headers.
+//
+// A traceparent is "version-traceid-parentid-flags", for example:
+//
+//	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// It's rejected if it appears more than once, if any field isn't lowercase
+// hex of the right length, if the version is "ff", if the trace or parent
+// ID is all zeros, or if a version 00 header has trailing data. Headers of
+// a later version may carry extra fields after a '-', which are ignored.
+//
+// tracestate is only kept alongside a valid traceparent. Repeated headers
+// are combined, empty members are dropped, and the whole list is discarded
+// if it has more than 32 members or a member isn't a key=value pair.
+func (W3CPropagator) Extract(h http.Header) (SpanContext, bool) {
+	values := h.Values("Traceparent")
+	if len(values) != 1 {
+		return SpanContext{}, false
+	}
+
+	sc, ok := parseTraceparent(values[0])
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc.TraceState = parseTracestate(h.Values("Tracestate"))
+	return sc, true
+}
+
+// Inject writes the traceparent and, if present, tracestate headers
+func (W3CPropagator) Inject(h http.Header, sc SpanContext) {
+	h.Set("Traceparent", fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags))
+	if sc.TraceState != "" {
+		h.Set("Tracestate", sc.TraceState)
+	} else {
+		h.Del("Tracestate")
+	}
+}
+
+// parseTraceparent parses a traceparent header value (see W3CPropagator.Extract)
+func parseTraceparent(v string) (SpanContext, bool) {
+	v = strings.TrimSpace(v)
+
+	// version(2) - trace-id(32) - parent-id(16) - flags(2)
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(v) < length || v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return SpanContext{}, false
+	}
+
+	version, traceID, parentID, flags := v[0:2], v[3:35], v[36:52], v[53:55]
+	if !isLowerHex(version) || version == "ff" {
+		return SpanContext{}, false
+	}
+	if len(v) > length && (version == "00" || v[length] != '-') {
+		return SpanContext{}, false
+	}
+	if !isLowerHex(traceID) || isZeroHex(traceID) {
+		return SpanContext{}, false
+	}
+	if !isLowerHex(parentID) || isZeroHex(parentID) {
+		return SpanContext{}, false
+	}
+	if !isLowerHex(flags) {
+		return SpanContext{}, false
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return SpanContext{TraceID: traceID, SpanID: parentID, Flags: b[0]}, true
+}
+
+// parseTracestate combines and validates tracestate header values
+// (see W3CPropagator.Extract)
+func parseTracestate(values []string) string {
+	var members []string
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			member = strings.TrimSpace(member)
+			if member == "" {
+				continue
+			}
+			if eq := strings.IndexByte(member, '='); eq <= 0 || eq == len(member)-1 {
+				return ""
+			}
+			members = append(members, member)
+		}
+	}
+	if len(members) > 32 {
+		return ""
+	}
+	return strings.Join(members, ",")
+}
+
+// isLowerHex reports whether s consists only of lowercase hex digits
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZeroHex reports whether s consists only of '0'
+func isZeroHex(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// randomIDs generates random trace and span IDs
+type randomIDs struct{}
+
+func (randomIDs) TraceID() string { return randomHex(16) }
+func (randomIDs) SpanID() string  { return randomHex(8) }
+
+// randomHex returns n random bytes as lowercase hex, never all zeros
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			panic(fmt.Sprintf("router: failed to generate random ID: %v", err))
+		}
+		if s := hex.EncodeToString(b); !isZeroHex(s) {
+			return s
+		}
+	}
+}
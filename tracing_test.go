@@ -0,0 +1,172 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fixedIDs struct{}
+
+func (fixedIDs) TraceID() string { return "0af7651916cd43dd8448eb211c80319c" }
+func (fixedIDs) SpanID() string  { return "b7ad6b7169203331" }
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+		flags byte
+	}{
+		{"valid sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, 0x01},
+		{"valid unsampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, 0x00},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, 0x01},
+		{"version ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, 0},
+		{"version 00 with trailing data", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, 0},
+		{"future version without separator", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x", false, 0},
+		{"uppercase hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, 0},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, 0},
+		{"zero parent id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, 0},
+		{"short trace id", "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", false, 0},
+		{"bad flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g", false, 0},
+		{"empty", "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := parseTraceparent(tt.value)
+			if ok != tt.valid {
+				t.Fatalf("expected valid=%v, got %v", tt.valid, ok)
+			}
+			if !ok {
+				return
+			}
+			if sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID != "00f067aa0ba902b7" {
+				t.Errorf("unexpected IDs %+v", sc)
+			}
+			if sc.Flags != tt.flags {
+				t.Errorf("expected flags %02x, got %02x", tt.flags, sc.Flags)
+			}
+		})
+	}
+}
+
+func TestParseTracestate(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"congo=t61rcWkgMzE"}, "congo=t61rcWkgMzE"},
+		{[]string{"rojo=00f067aa0ba902b7", "congo=t61rcWkgMzE"}, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE"},
+		{[]string{"a=1, ,b=2"}, "a=1,b=2"},
+		{[]string{"invalid"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseTracestate(tt.values); got != tt.want {
+			t.Errorf("parseTracestate(%q): expected %q, got %q", tt.values, tt.want, got)
+		}
+	}
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	r := New()
+	r.Use(Tracing(WithIDGenerator(fixedIDs{})))
+
+	var sc SpanContext
+	var fromRequest SpanContext
+	r.Get("/", func(c *Context) error {
+		sc, _ = c.SpanContext()
+		fromRequest, _ = SpanContextFromContext(c.Request.Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "congo=t61rcWkgMzE")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected incoming trace ID to be kept, got %s", sc.TraceID)
+	}
+	if sc.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("expected parent ID from incoming header, got %s", sc.ParentID)
+	}
+	if sc.SpanID != "b7ad6b7169203331" {
+		t.Errorf("expected a new span ID, got %s", sc.SpanID)
+	}
+	if fromRequest != sc {
+		t.Errorf("expected request context to carry the span context, got %+v", fromRequest)
+	}
+
+	if got := w.Header().Get("traceparent"); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-b7ad6b7169203331-01" {
+		t.Errorf("unexpected response traceparent %q", got)
+	}
+	if got := w.Header().Get("tracestate"); got != "congo=t61rcWkgMzE" {
+		t.Errorf("unexpected response tracestate %q", got)
+	}
+}
+
+func TestTracingStartsNewTrace(t *testing.T) {
+	r := New()
+	r.Use(Tracing())
+
+	var traceID, spanID string
+	r.Get("/", func(c *Context) error {
+		traceID, spanID = c.TraceID(), c.SpanID()
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "not-a-traceparent")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if len(traceID) != 32 || !isLowerHex(traceID) {
+		t.Errorf("expected a generated trace ID, got %q", traceID)
+	}
+	if len(spanID) != 16 || !isLowerHex(spanID) {
+		t.Errorf("expected a generated span ID, got %q", spanID)
+	}
+	if want := "00-" + traceID + "-" + spanID + "-01"; w.Header().Get("traceparent") != want {
+		t.Errorf("expected response traceparent %q, got %q", want, w.Header().Get("traceparent"))
+	}
+}
+
+type headerPropagator struct{}
+
+func (headerPropagator) Extract(h http.Header) (SpanContext, bool) {
+	if id := h.Get("X-Trace"); id != "" {
+		return SpanContext{TraceID: id, SpanID: "1111111111111111"}, true
+	}
+	return SpanContext{}, false
+}
+
+func (headerPropagator) Inject(h http.Header, sc SpanContext) {
+	h.Set("X-Trace", sc.TraceID)
+}
+
+func TestTracingWithCustomPropagator(t *testing.T) {
+	r := New()
+	r.Use(Tracing(WithPropagator(headerPropagator{}), WithIDGenerator(fixedIDs{})))
+	r.Get("/", func(c *Context) error {
+		return c.String(http.StatusOK, c.TraceID())
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Trace", "custom-trace")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "custom-trace" {
+		t.Errorf("expected custom trace ID, got %q", w.Body.String())
+	}
+	if w.Header().Get("X-Trace") != "custom-trace" {
+		t.Errorf("expected custom propagator to inject the header")
+	}
+	if w.Header().Get("traceparent") != "" {
+		t.Errorf("expected no W3C header with a custom propagator")
+	}
+}